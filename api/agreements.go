@@ -0,0 +1,145 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// BookedLoan is the result of booking a loan from a trade agreement
+type BookedLoan struct {
+	AgreementId string
+	Message     string       // message of the ledger response
+	Loan        *models.Loan // the new loan, nil if it could not be found
+}
+
+// NewLoanProposal builds a loan proposal from the trade block of a trade
+// agreement (instrument, quantity, rate, collateral and transacting parties)
+// and our settlement instruction. The party role of the settlement
+// instruction is filled in from the agreement when left empty
+func (c *Client) NewLoanProposal(agreement *models.Agreement, settlement models.PartySettlementInstruction) (*models.LoanProposal, error) {
+	partyId := c.cfg.General.Party_Id
+	if partyId == "" {
+		return nil, errPartyId
+	}
+
+	role, ok := agreement.Trade.PartyRole(partyId)
+	if !ok {
+		return nil, fmt.Errorf("party [%s] is not a transacting party of trade agreement [%s]", partyId, agreement.AgreementId)
+	}
+
+	if settlement.PartyRole == "" {
+		settlement.PartyRole = role
+	} else if settlement.PartyRole != role {
+		return nil, fmt.Errorf("settlement instruction is for %s but party [%s] is the %s of trade agreement [%s]", settlement.PartyRole, partyId, role, agreement.AgreementId)
+	}
+
+	return &models.LoanProposal{
+		Trade:      agreement.Trade,
+		Settlement: []models.PartySettlementInstruction{settlement},
+	}, nil
+}
+
+// BookLoanFromAgreement proposes a loan built from a trade agreement and our
+// settlement instruction, through the same path as ProposeLoan. It then looks
+// up the new loan by the agreement's venue reference so that it can be
+// linked back to the agreement
+func (c *Client) BookLoanFromAgreement(ctx context.Context, agreementId string, settlement models.PartySettlementInstruction) (*BookedLoan, error) {
+	agreement, err := c.GetAgreement(ctx, agreementId)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving trade agreement [%s]: %w", agreementId, err)
+	}
+
+	if agreement.Status == models.AgreementStatusCanceled {
+		return nil, fmt.Errorf("trade agreement [%s] is CANCELED and cannot be booked", agreementId)
+	}
+
+	proposal, err := c.NewLoanProposal(agreement, settlement)
+	if err != nil {
+		return nil, err
+	}
+
+	// Loans created from now on are candidates for the new loan, allowing
+	// for some clock skew with the ledger
+	since := time.Now().Add(-time.Minute)
+
+	message, err := c.ProposeLoan(ctx, *proposal)
+	if err != nil {
+		return nil, fmt.Errorf("booking trade agreement [%s]: %w", agreementId, err)
+	}
+
+	booked := &BookedLoan{AgreementId: agreementId, Message: message}
+
+	booked.Loan, err = c.findBookedLoan(ctx, agreement, since)
+	if err != nil {
+		return booked, fmt.Errorf("looking up the loan booked from trade agreement [%s]: %w", agreementId, err)
+	}
+
+	return booked, nil
+}
+
+// findBookedLoan finds the PROPOSED loan created since the given time with
+// the same venue reference, or failing that the same trade terms, as the
+// trade agreement. It returns nil if there is none
+func (c *Client) findBookedLoan(ctx context.Context, agreement *models.Agreement, since time.Time) (*models.Loan, error) {
+	loans, err := c.FindProposedLoans(ctx, []*models.Trade{&agreement.Trade}, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return loans[0], nil
+}
+
+// FindProposedLoans finds the PROPOSED loans created since the given time for
+// a batch of proposed trades, in a single pass over the new loans. A loan
+// matches a trade with the same venue reference, or failing that the same
+// trade terms, and is matched to one trade at most. The loan of a trade is
+// nil if there is none
+func (c *Client) FindProposedLoans(ctx context.Context, trades []*models.Trade, since time.Time) ([]*models.Loan, error) {
+	loans := make([]*models.Loan, len(trades))
+	missing := len(trades)
+
+	it := Iterate[models.Loan](c, c.cfg.Endpoints.Loans, ListOptions{Since: since})
+
+	for missing > 0 && it.Next(ctx) {
+		loan := it.Item()
+
+		if loan.LoanStatus != models.LoanStatusProposed {
+			continue
+		}
+
+		for i, trade := range trades {
+			if loans[i] == nil && sameTrade(&loan.Trade, trade) {
+				loans[i] = &loan
+				missing--
+				break
+			}
+		}
+	}
+
+	return loans, it.Err()
+}
+
+// sameTrade reports whether two trades are the same venue trade
+func sameTrade(a *models.Trade, b *models.Trade) bool {
+	if ref := venueRefId(b); ref != "" {
+		return venueRefId(a) == ref
+	}
+
+	ia, ib := a.Instrument, b.Instrument
+
+	return ia.Ticker == ib.Ticker && ia.Cusip == ib.Cusip && ia.Isin == ib.Isin && ia.Sedol == ib.Sedol &&
+		ia.Figi == ib.Figi && a.Quantity == b.Quantity && a.TradeDate == b.TradeDate
+}
+
+// venueRefId returns the venue reference of a trade, if any
+func venueRefId(t *models.Trade) string {
+	if t.ExecutionVenue == nil || t.ExecutionVenue.Platform == nil {
+		return ""
+	}
+
+	return t.ExecutionVenue.Platform.VenueRefId
+}
@@ -3,37 +3,132 @@ package api
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/EquiLend/1Source-Go/models"
 	"github.com/Nerzal/gocloak/v13"
 )
 
+// Supported values of auth_type in the [authentication] section of the
+// configuration TOML file. An empty auth_type selects client_secret when
+// a client_secret is configured and public otherwise
+const (
+	AuthTypeClientSecret = "client_secret"
+	AuthTypePublic       = "public"
+)
+
+// Supported values of grant_type in the [authentication] section of the
+// configuration TOML file. An empty grant_type selects password
+const (
+	GrantTypePassword          = "password"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
 // GetAuthToken logs into KeyCloak using credentials from the configuration
 // TOML file to retrieve an Auth Token, which is used in subsequent calls to
 // the 1Source REST API
-func GetAuthToken(cfg *models.AppConfig) (*gocloak.JWT, error) {
-	var token *gocloak.JWT
-	var err error
-
-	// Log into KeyCloak to get Auth Token
-	log.Println("Logging into KeyCloak to get Auth Token")
-	client := gocloak.NewClient(cfg.General.Auth_URL)
-	ctx := context.Background()
-
-	token, err = client.Login(
-		ctx,
-		cfg.Authentication.Client_Id,
-		cfg.Authentication.Client_Secret,
-		cfg.General.Realm_Name,
-		cfg.Authentication.Username,
-		cfg.Authentication.Password)
-
+// A KeyCloak error response is returned as *APIError. Long-running callers
+// should use a TokenSource, which also refreshes the Auth Token.
+func GetAuthToken(ctx context.Context, cfg *models.AppConfig) (*gocloak.JWT, error) {
+	ts, err := NewTokenSource(cfg)
 	if err != nil {
-		log.Panic("Error retrieving Auth token", err)
-	} else {
-		log.Println("Successfully received Auth token")
+		return nil, err
+	}
+
+	return ts.Token(ctx)
+}
+
+// ValidateAuthentication checks that the [authentication] section of the
+// configuration TOML file asks for a supported auth_type and grant_type
+// combination and contains the credentials it needs
+func ValidateAuthentication(cfg *models.AppConfig) error {
+	auth := cfg.Authentication
+	authType := authType(cfg)
+	grantType := grantType(cfg)
+
+	if auth.Client_Id == "" {
+		return errors.New("authentication: client_id is required")
+	}
+
+	switch authType {
+	case AuthTypeClientSecret:
+		if auth.Client_Secret == "" {
+			return errors.New("authentication: auth_type 'client_secret' requires a client_secret")
+		}
+	case AuthTypePublic:
+	default:
+		return fmt.Errorf("authentication: unsupported auth_type '%s' (expected '%s' or '%s')",
+			auth.Auth_Type, AuthTypeClientSecret, AuthTypePublic)
+	}
+
+	switch grantType {
+	case GrantTypePassword:
+		if auth.Username == "" || auth.Password == "" {
+			return errors.New("authentication: grant_type 'password' requires a username and password")
+		}
+	case GrantTypeClientCredentials:
+		if authType == AuthTypePublic {
+			return errors.New("authentication: grant_type 'client_credentials' is not supported with auth_type 'public'")
+		}
+	case GrantTypeRefreshToken:
+		if auth.Refresh_Token == "" {
+			return errors.New("authentication: grant_type 'refresh_token' requires a refresh_token")
+		}
+	default:
+		return fmt.Errorf("authentication: unsupported grant_type '%s' (expected '%s', '%s' or '%s')",
+			auth.Grant_Type, GrantTypePassword, GrantTypeClientCredentials, GrantTypeRefreshToken)
+	}
+
+	return nil
+}
+
+// authType returns the effective auth_type of the configuration
+func authType(cfg *models.AppConfig) string {
+	if cfg.Authentication.Auth_Type != "" {
+		return cfg.Authentication.Auth_Type
+	}
+
+	if cfg.Authentication.Client_Secret != "" {
+		return AuthTypeClientSecret
+	}
+
+	return AuthTypePublic
+}
+
+// grantType returns the effective grant_type of the configuration
+func grantType(cfg *models.AppConfig) string {
+	if cfg.Authentication.Grant_Type != "" {
+		return cfg.Authentication.Grant_Type
+	}
+
+	return GrantTypePassword
+}
+
+// clientSecret returns the client secret to send to KeyCloak, which is
+// empty for public clients
+func clientSecret(cfg *models.AppConfig) string {
+	if authType(cfg) == AuthTypePublic {
+		return ""
+	}
+
+	return cfg.Authentication.Client_Secret
+}
+
+// authError converts a gocloak API error into an *APIError so that callers
+// can inspect the KeyCloak status code the same way as for the ledger
+func authError(cfg *models.AppConfig, err error) error {
+	var kcErr *gocloak.APIError
+	if errors.As(err, &kcErr) {
+		return &APIError{
+			StatusCode: kcErr.Code,
+			Method:     http.MethodPost,
+			Path:       "/realms/" + cfg.General.Realm_Name + "/protocol/openid-connect/token",
+			Message:    kcErr.Message,
+		}
 	}
 
-	return token, err
+	return err
 }
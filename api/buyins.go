@@ -0,0 +1,87 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// SubmitBuyin will perform an HTTP POST operation against the 1Source REST
+// API to submit a buy-in of securities on an OPEN loan as its lender
+// A buy-in is only possible for recalls which are past their due date, and
+// its quantity must not exceed the quantity those recalls still have open.
+func (c *Client) SubmitBuyin(ctx context.Context, loanId string, proposal models.BuyinProposal) (string, error) {
+	if proposal.Quantity <= 0 {
+		return "", errors.New("buy-in quantity must be positive")
+	}
+
+	if proposal.Price.Value <= 0 || proposal.Price.Currency == "" {
+		return "", errors.New("buy-in requires a positive price and its currency")
+	}
+
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionBuyin)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.requireRole(loan, models.PartyRoleLender, "buy in"); err != nil {
+		return "", err
+	}
+
+	recalls, err := c.ListOpenRecalls(ctx, loanId)
+	if err != nil {
+		return "", fmt.Errorf("retrieving open recalls of loan [%s]: %w", loanId, err)
+	}
+
+	var overdue int64
+	now := time.Now()
+	for _, r := range recalls {
+		if days, err := r.DaysUntilDue(now); err == nil && days < 0 {
+			overdue += r.Open()
+		}
+	}
+
+	if overdue == 0 {
+		return "", fmt.Errorf("loan [%s] has no overdue recall to buy in", loanId)
+	}
+
+	if proposal.Quantity > overdue {
+		return "", fmt.Errorf("buy-in quantity %d exceeds the overdue recalled quantity %d of loan [%s]", proposal.Quantity, overdue, loanId)
+	}
+
+	return c.postJSON(ctx, c.cfg.Endpoints.Loans+"/"+loanId+"/buyins", proposal, "submitting buy-in of loan ["+loanId+"]")
+}
+
+// AcceptBuyin will perform an HTTP POST operation against the 1Source REST
+// API to accept a buy-in submitted by the lender, as the borrower of the loan
+func (c *Client) AcceptBuyin(ctx context.Context, buyinId string) (string, error) {
+	buyin, err := c.GetBuyin(ctx, buyinId)
+	if err != nil {
+		return "", fmt.Errorf("retrieving buy-in [%s]: %w", buyinId, err)
+	}
+
+	if !buyin.Status.CanTransition(models.BuyinStatusAccepted) {
+		return "", fmt.Errorf("buy-in [%s] is in %s state and cannot be accepted", buyinId, buyin.Status)
+	}
+
+	loan, err := c.GetLoan(ctx, buyin.LoanId)
+	if err != nil {
+		return "", fmt.Errorf("retrieving loan [%s] of buy-in [%s]: %w", buyin.LoanId, buyinId, err)
+	}
+
+	if err := loan.CheckAction(models.LoanActionBuyin); err != nil {
+		return "", err
+	}
+
+	if err := c.requireRole(loan, models.PartyRoleBorrower, "accept buy-ins of"); err != nil {
+		return "", err
+	}
+
+	endPoint := c.cfg.Endpoints.Loans + "/" + buyin.LoanId + "/buyins/" + buyinId + "/accept"
+
+	return c.postJSON(ctx, endPoint, nil, "accepting buy-in ["+buyinId+"]")
+}
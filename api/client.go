@@ -0,0 +1,264 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Default connection settings, used when the [connection] section of the
+// configuration TOML file leaves a value unset
+const (
+	DefaultTimeout             = 15 * time.Second
+	DefaultDialTimeout         = 10 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 10
+)
+
+// Client is a reusable client for the 1Source REST API. It owns a single
+// pooled http.Transport shared by every call made through it, so connections
+// to the 1Source REST API are kept alive and reused between calls
+type Client struct {
+	cfg        *models.AppConfig
+	baseURL    *url.URL
+	tokens     *TokenSource
+	retry      RetryPolicy
+	httpClient *http.Client
+	dryRun     *DryRun
+}
+
+// NewClient creates a Client from the application configuration and the
+// TokenSource supplying its Auth Tokens
+func NewClient(cfg *models.AppConfig, tokens *TokenSource) (*Client, error) {
+	var baseURL *url.URL
+
+	if cfg.Endpoints.Base != "" {
+		var err error
+
+		baseURL, err = url.Parse(cfg.Endpoints.Base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base endpoint [%s]: %w", cfg.Endpoints.Base, err)
+		}
+	}
+
+	conn := cfg.Connection
+
+	dialer := &net.Dialer{
+		Timeout:   seconds(conn.Dial_Timeout, DefaultDialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        count(conn.Max_Idle_Conns, DefaultMaxIdleConns),
+		MaxIdleConnsPerHost: count(conn.Max_Idle_Conns_Per_Host, DefaultMaxIdleConnsPerHost),
+		IdleConnTimeout:     seconds(conn.Idle_Conn_Timeout, DefaultIdleConnTimeout),
+		TLSHandshakeTimeout: seconds(conn.TLS_Handshake_Timeout, DefaultTLSHandshakeTimeout),
+	}
+
+	client := &Client{
+		cfg:     cfg,
+		baseURL: baseURL,
+		tokens:  tokens,
+		retry:   NewRetryPolicy(cfg),
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   seconds(conn.Timeout, DefaultTimeout),
+		},
+	}
+
+	return client, nil
+}
+
+// Config returns the application configuration the Client was built from
+func (c *Client) Config() *models.AppConfig {
+	return c.cfg
+}
+
+// RetryPolicy returns the retry policy used by the Client
+func (c *Client) RetryPolicy() RetryPolicy {
+	return c.retry
+}
+
+// WithRetryPolicy returns a copy of the Client which uses the given retry
+// policy. The copy shares the connection pool and TokenSource of c, so
+// callers can opt into retrying POSTs for individual calls:
+//
+//	policy := client.RetryPolicy()
+//	policy.RetryPost = true
+//	client.WithRetryPolicy(policy).ProposeLoan(ctx, proposal)
+func (c *Client) WithRetryPolicy(policy RetryPolicy) *Client {
+	clone := *c
+	clone.retry = policy
+
+	return &clone
+}
+
+// CloseIdleConnections closes any pooled connections which are not in use
+func (c *Client) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// resolve builds the absolute URL of an endpoint. Absolute endpoints are
+// used as-is, relative ones are resolved against Endpoints.Base
+func (c *Client) resolve(endPoint string) (string, error) {
+	ref, err := url.Parse(endPoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint [%s]: %w", endPoint, err)
+	}
+
+	if ref.IsAbs() {
+		return ref.String(), nil
+	}
+
+	if c.baseURL == nil {
+		return "", fmt.Errorf("relative endpoint [%s] requires a base endpoint", endPoint)
+	}
+
+	return c.baseURL.ResolveReference(ref).String(), nil
+}
+
+// do sends an HTTP request to the 1Source REST API over the shared transport
+// and returns the response body. A non-2xx response is returned as *APIError.
+// Failed requests are retried according to the Client's RetryPolicy
+func (c *Client) do(ctx context.Context, method string, endPoint string, body []byte) ([]byte, error) {
+	apiEndPoint, err := c.resolve(endPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.dryRun.intercepts(method) {
+		return nil, c.dryRunRequest(ctx, method, apiEndPoint, body)
+	}
+
+	attempts := c.retry.attempts(method)
+
+	for attempt := 1; ; attempt++ {
+		data, err := c.authorized(ctx, method, apiEndPoint, body)
+
+		if err == nil || attempt >= attempts || ctx.Err() != nil || !c.retry.retryable(err) {
+			return data, err
+		}
+
+		log.Printf("Retrying %s %s (attempt %d of %d): %s", method, apiEndPoint, attempt+1, attempts, err)
+
+		if werr := c.retry.Wait(ctx, attempt, err); werr != nil {
+			return nil, fmt.Errorf("%s %s: %w", method, apiEndPoint, werr)
+		}
+	}
+}
+
+// authorized sends an HTTP request with a valid Auth Token. If the Auth
+// Token is rejected with a 401, the request is retried once with a new one
+func (c *Client) authorized(ctx context.Context, method string, apiEndPoint string, body []byte) ([]byte, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.send(ctx, method, apiEndPoint, body, token.AccessToken)
+	if !IsUnauthorized(err) {
+		return data, err
+	}
+
+	log.Println("Auth token rejected, retrying with a new Auth token")
+	c.tokens.Invalidate(token.AccessToken)
+
+	token, err = c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.send(ctx, method, apiEndPoint, body, token.AccessToken)
+}
+
+// newRequest creates an HTTP request to the 1Source REST API with its headers
+func newRequest(ctx context.Context, method string, apiEndPoint string, body []byte, accessToken string) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, apiEndPoint, reader)
+	if err != nil {
+		log.Println("Error creating new HTTP Request: ", err)
+		return nil, fmt.Errorf("creating %s request for %s: %w", method, apiEndPoint, err)
+	}
+
+	request.Header.Set("Authorization", `Bearer `+accessToken)
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	return request, nil
+}
+
+// send performs a single HTTP request with the given access token
+func (c *Client) send(ctx context.Context, method string, apiEndPoint string, body []byte, accessToken string) ([]byte, error) {
+	request, err := newRequest(ctx, method, apiEndPoint, body, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Calling API endpoint: %s %s", method, apiEndPoint)
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		log.Println("Error in response.\n[ERR] -", err)
+		return nil, fmt.Errorf("%s %s: %w", method, apiEndPoint, err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Println("Error closing Body.\n[ERR] -", err)
+		}
+	}(response.Body)
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		log.Println("Error reading response body.\n[ERR] -", err)
+		return nil, fmt.Errorf("reading response of %s %s: %w", method, apiEndPoint, err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiErr := newAPIError(method, apiEndPoint, response.StatusCode, data)
+		apiErr.RetryAfter = ParseRetryAfter(response.Header.Get("Retry-After"))
+		log.Println("Error in response status. [ERR] -", apiErr)
+		return nil, apiErr
+	}
+
+	return data, nil
+}
+
+// seconds converts a number of seconds from the configuration TOML file into
+// a time.Duration, falling back to def when it is unset
+func seconds(value uint32, def time.Duration) time.Duration {
+	if value == 0 {
+		return def
+	}
+
+	return time.Duration(value) * time.Second
+}
+
+// count returns value, falling back to def when it is unset
+func count(value int, def int) int {
+	if value <= 0 {
+		return def
+	}
+
+	return value
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// testPartyId is the party the test Client acts for
+const testPartyId = "TLEN-US"
+
+// newTestClient creates a Client of a test ledger served by handler, with an
+// Auth Token which does not expire and short retry delays
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &models.AppConfig{}
+	cfg.General.Party_Id = testPartyId
+	cfg.Endpoints.Base = server.URL + "/v1/ledger/"
+	cfg.Endpoints.Parties = server.URL + "/v1/ledger/parties"
+	cfg.Endpoints.Events = server.URL + "/v1/ledger/events"
+	cfg.Endpoints.Agreements = server.URL + "/v1/ledger/agreements"
+	cfg.Endpoints.Loans = server.URL + "/v1/ledger/loans"
+	cfg.Endpoints.Rerates = server.URL + "/v1/ledger/rerates"
+	cfg.Endpoints.Returns = server.URL + "/v1/ledger/returns"
+	cfg.Endpoints.Recalls = server.URL + "/v1/ledger/recalls"
+	cfg.Endpoints.Buyins = server.URL + "/v1/ledger/buyins"
+	cfg.Retry.Max_Attempts = 3
+	cfg.Retry.Base_Delay_Ms = 1
+	cfg.Retry.Max_Delay_Ms = 5
+
+	tokens := &TokenSource{
+		cfg:    cfg,
+		token:  &gocloak.JWT{AccessToken: "token"},
+		expiry: time.Now().Add(time.Hour),
+	}
+
+	client, err := NewClient(cfg, tokens)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	return client
+}
+
+// writeJSON writes v as the JSON body of a test ledger response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// testLoan returns an OPEN loan between the test party and a borrower
+func testLoan(loanId string) models.Loan {
+	return models.Loan{
+		LoanId:     loanId,
+		LoanStatus: models.LoanStatusOpen,
+		Trade: models.Trade{
+			Quantity: 10000,
+			TransactingParties: []models.TransactingParty{
+				{PartyRole: models.PartyRoleLender, Party: models.Party{PartyId: testPartyId}},
+				{PartyRole: models.PartyRoleBorrower, Party: models.Party{PartyId: "TBORR-US"}},
+			},
+		},
+	}
+}
@@ -0,0 +1,117 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrDryRun is returned in place of the response of a mutating request
+// which was printed by a DryRun instead of being sent
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// DryRun prints the mutating requests of a Client instead of sending them.
+// GET and HEAD requests are still sent, so that authentication, state
+// lookups and validation happen as they would for real
+type DryRun struct {
+	out      io.Writer
+	mu       sync.Mutex
+	requests int
+}
+
+// NewDryRun returns a DryRun which prints requests to out
+func NewDryRun(out io.Writer) *DryRun {
+	return &DryRun{out: out}
+}
+
+// Requests returns the number of requests printed, which is zero when the
+// command failed before reaching the 1Source REST API
+func (d *DryRun) Requests() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.requests
+}
+
+// WithDryRun returns a copy of the Client which hands its mutating requests
+// to the DryRun instead of sending them
+func (c *Client) WithDryRun(d *DryRun) *Client {
+	clone := *c
+	clone.dryRun = d
+
+	return &clone
+}
+
+// IsDryRun reports whether the Client prints its mutating requests instead
+// of sending them
+func (c *Client) IsDryRun() bool {
+	return c.dryRun != nil
+}
+
+// intercepts reports whether the request is printed by the dry run
+func (d *DryRun) intercepts(method string) bool {
+	return d != nil && method != http.MethodGet && method != http.MethodHead
+}
+
+// print writes the request as it would be sent, with the Auth Token
+// redacted, and returns ErrDryRun
+func (d *DryRun) print(request *http.Request, body []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.requests++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: %s %s\n", request.Method, request.URL)
+
+	names := make([]string, 0, len(request.Header))
+	for name := range request.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		value := request.Header.Get(name)
+		if name == "Authorization" {
+			value = "Bearer [REDACTED]"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, value)
+	}
+
+	if len(body) > 0 {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err == nil {
+			body = indented.Bytes()
+		}
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+
+	if _, err := io.WriteString(d.out, b.String()); err != nil {
+		return fmt.Errorf("printing dry run request: %w", err)
+	}
+
+	return ErrDryRun
+}
+
+// dryRunRequest builds the request as send would and hands it to the DryRun
+func (c *Client) dryRunRequest(ctx context.Context, method string, apiEndPoint string, body []byte) error {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	request, err := newRequest(ctx, method, apiEndPoint, body, token.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	return c.dryRun.print(request, body)
+}
@@ -0,0 +1,133 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// getOne performs an HTTP GET of a single entity and decodes it into a T
+func getOne[T any](ctx context.Context, c *Client, endPoint string) (*T, error) {
+	data, err := c.do(ctx, http.MethodGet, endPoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entity T
+	if err := json.Unmarshal(data, &entity); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", endPoint, err)
+	}
+
+	return &entity, nil
+}
+
+// GetResource retrieves the entity at a resource URI, such as the
+// resourceUri of an event, and decodes it into v. A URI without a host is
+// resolved against Endpoints.Base
+func (c *Client) GetResource(ctx context.Context, resourceUri string, v any) error {
+	data, err := c.do(ctx, http.MethodGet, resourceUri, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding %s: %w", resourceUri, err)
+	}
+
+	return nil
+}
+
+// GetLoan retrieves a loan by loan_id
+func (c *Client) GetLoan(ctx context.Context, loanId string) (*models.Loan, error) {
+	return getOne[models.Loan](ctx, c, c.cfg.Endpoints.Loans+"/"+loanId)
+}
+
+// GetLoanHistory retrieves every version of a loan by loan_id
+func (c *Client) GetLoanHistory(ctx context.Context, loanId string) (models.Loans, error) {
+	history, err := getOne[models.Loans](ctx, c, c.cfg.Endpoints.Loans+"/"+loanId+"/history")
+	if err != nil {
+		return nil, err
+	}
+
+	return *history, nil
+}
+
+// GetAgreement retrieves a trade agreement by agreement_id
+func (c *Client) GetAgreement(ctx context.Context, agreementId string) (*models.Agreement, error) {
+	return getOne[models.Agreement](ctx, c, c.cfg.Endpoints.Agreements+"/"+agreementId)
+}
+
+// GetParty retrieves a party by party_id
+func (c *Client) GetParty(ctx context.Context, partyId string) (*models.Party, error) {
+	return getOne[models.Party](ctx, c, c.cfg.Endpoints.Parties+"/"+partyId)
+}
+
+// GetEvent retrieves an event by event_id
+func (c *Client) GetEvent(ctx context.Context, eventId uint64) (*models.Event, error) {
+	return getOne[models.Event](ctx, c, c.cfg.Endpoints.Events+"/"+strconv.FormatUint(eventId, 10))
+}
+
+// GetRerate retrieves a rerate by rerate_id
+func (c *Client) GetRerate(ctx context.Context, rerateId string) (*models.Rerate, error) {
+	return getOne[models.Rerate](ctx, c, c.cfg.Endpoints.Rerates+"/"+rerateId)
+}
+
+// GetReturn retrieves a return by return_id
+func (c *Client) GetReturn(ctx context.Context, returnId string) (*models.Return, error) {
+	return getOne[models.Return](ctx, c, c.cfg.Endpoints.Returns+"/"+returnId)
+}
+
+// GetRecall retrieves a recall by recall_id
+func (c *Client) GetRecall(ctx context.Context, recallId string) (*models.Recall, error) {
+	return getOne[models.Recall](ctx, c, c.cfg.Endpoints.Recalls+"/"+recallId)
+}
+
+// GetBuyin retrieves a buyin by buyin_id
+func (c *Client) GetBuyin(ctx context.Context, buyinId string) (*models.Buyin, error) {
+	return getOne[models.Buyin](ctx, c, c.cfg.Endpoints.Buyins+"/"+buyinId)
+}
+
+// ListEvents retrieves the events the user is authorized to view
+func (c *Client) ListEvents(ctx context.Context, opts ListOptions) (models.Events, error) {
+	return Collect(ctx, Iterate[models.Event](c, c.cfg.Endpoints.Events, opts))
+}
+
+// ListParties retrieves the parties the user is authorized to view
+func (c *Client) ListParties(ctx context.Context, opts ListOptions) (models.Parties, error) {
+	return Collect(ctx, Iterate[models.Party](c, c.cfg.Endpoints.Parties, opts))
+}
+
+// ListAgreements retrieves the trade agreements the user is authorized to view
+func (c *Client) ListAgreements(ctx context.Context, opts ListOptions) (models.Agreements, error) {
+	return Collect(ctx, Iterate[models.Agreement](c, c.cfg.Endpoints.Agreements, opts))
+}
+
+// ListLoans retrieves the loans the user is authorized to view
+func (c *Client) ListLoans(ctx context.Context, opts ListOptions) (models.Loans, error) {
+	return Collect(ctx, Iterate[models.Loan](c, c.cfg.Endpoints.Loans, opts))
+}
+
+// ListRerates retrieves the rerates the user is authorized to view
+func (c *Client) ListRerates(ctx context.Context, opts ListOptions) (models.Rerates, error) {
+	return Collect(ctx, Iterate[models.Rerate](c, c.cfg.Endpoints.Rerates, opts))
+}
+
+// ListReturns retrieves the returns the user is authorized to view
+func (c *Client) ListReturns(ctx context.Context, opts ListOptions) (models.Returns, error) {
+	return Collect(ctx, Iterate[models.Return](c, c.cfg.Endpoints.Returns, opts))
+}
+
+// ListRecalls retrieves the recalls the user is authorized to view
+func (c *Client) ListRecalls(ctx context.Context, opts ListOptions) (models.Recalls, error) {
+	return Collect(ctx, Iterate[models.Recall](c, c.cfg.Endpoints.Recalls, opts))
+}
+
+// ListBuyins retrieves the buyins the user is authorized to view
+func (c *Client) ListBuyins(ctx context.Context, opts ListOptions) (models.Buyins, error) {
+	return Collect(ctx, Iterate[models.Buyin](c, c.cfg.Endpoints.Buyins, opts))
+}
@@ -0,0 +1,100 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// APIError is returned when the 1Source REST API answers with a non-2xx
+// status. It carries the status code, path, timestamp and server message,
+// decoded from the same JSON shape as models.LoanInitiationResponse.
+// RetryAfter holds the server's Retry-After header, if it sent one
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Timestamp  string
+	Message    string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	if e.Timestamp != "" {
+		return fmt.Sprintf("1Source API %s %s returned %d at %s: %s", e.Method, e.Path, e.StatusCode, e.Timestamp, msg)
+	}
+
+	return fmt.Sprintf("1Source API %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// RetryAfterDelay returns the server's Retry-After, implementing
+// RetryAfterError
+func (e *APIError) RetryAfterDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// newAPIError builds an APIError from a non-2xx response, using the JSON
+// error body when the 1Source REST API sent one
+func newAPIError(method string, apiEndPoint string, status int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: status,
+		Method:     method,
+		Path:       apiEndPoint,
+	}
+
+	if u, err := url.Parse(apiEndPoint); err == nil {
+		apiErr.Path = u.Path
+	}
+
+	var resp models.LoanInitiationResponse
+	if err := json.Unmarshal(body, &resp); err == nil {
+		apiErr.Timestamp = resp.Timestamp
+		apiErr.Message = resp.Message
+		if resp.Path != "" {
+			apiErr.Path = resp.Path
+		}
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+
+	return apiErr
+}
+
+// StatusCode returns the HTTP status code carried by an APIError anywhere in
+// err's chain, or 0 if there is none
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+
+	return 0
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether err is an APIError with status 409
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+// IsUnauthorized reports whether err is an APIError with status 401
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
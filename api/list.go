@@ -0,0 +1,188 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultPageSize is the number of entities requested per page when
+// ListOptions.Size is unset
+const DefaultPageSize = 100
+
+// ListOptions holds the paging and filtering query parameters supported by
+// the list endpoints (events, loans, agreements, ...) of the 1Source REST API
+type ListOptions struct {
+	Size   int       // page size, DefaultPageSize if unset
+	Offset int       // offset of the first entity to fetch
+	Since  time.Time // only entities at or after this time, if set
+	Before time.Time // only entities before this time, if set
+	Limit  int       // stop after this many entities, 0 fetches everything
+}
+
+// pageSize returns the page size to request, never more than the
+// remaining number of entities wanted
+func (o ListOptions) pageSize(remaining int) int {
+	size := o.Size
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	if o.Limit > 0 && remaining < size {
+		size = remaining
+	}
+
+	return size
+}
+
+// pageURL adds the paging and filtering query parameters to an endpoint
+func (o ListOptions) pageURL(endPoint string, size int, offset int) (string, error) {
+	u, err := url.Parse(endPoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint [%s]: %w", endPoint, err)
+	}
+
+	q := u.Query()
+	q.Set("size", strconv.Itoa(size))
+	q.Set("offset", strconv.Itoa(offset))
+
+	if !o.Since.IsZero() {
+		q.Set("since", o.Since.UTC().Format(time.RFC3339))
+	}
+
+	if !o.Before.IsZero() {
+		q.Set("before", o.Before.UTC().Format(time.RFC3339))
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Iterator lazily walks a paginated list endpoint of the 1Source REST API,
+// fetching the next page only when the current one is exhausted. It is used
+// like bufio.Scanner:
+//
+//	it := api.Iterate[json.RawMessage](client, cfg.Endpoints.Events, api.ListOptions{})
+//	for it.Next(ctx) {
+//		event := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	client   *Client
+	endPoint string
+	opts     ListOptions
+
+	page   []T
+	pos    int
+	offset int
+	seen   int
+	last   bool
+	item   T
+	err    error
+}
+
+// Iterate creates an Iterator over the entities of a list endpoint, decoding
+// each one into a T
+func Iterate[T any](c *Client, endPoint string, opts ListOptions) *Iterator[T] {
+	return &Iterator[T]{
+		client:   c,
+		endPoint: endPoint,
+		opts:     opts,
+		offset:   opts.Offset,
+	}
+}
+
+// Next advances the Iterator to the next entity, fetching a new page when
+// needed. It returns false when there are no more entities, the Limit has
+// been reached or an error occurred
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.opts.Limit > 0 && it.seen >= it.opts.Limit {
+		return false
+	}
+
+	for it.pos >= len(it.page) {
+		if it.last {
+			return false
+		}
+
+		if err := it.fetch(ctx); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.item = it.page[it.pos]
+	it.pos++
+	it.seen++
+
+	return true
+}
+
+// Item returns the current entity
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the first error encountered by the Iterator
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// fetch retrieves the next page of entities
+func (it *Iterator[T]) fetch(ctx context.Context) error {
+	size := it.opts.pageSize(it.opts.Limit - it.seen)
+
+	pageURL, err := it.opts.pageURL(it.endPoint, size, it.offset)
+	if err != nil {
+		return err
+	}
+
+	data, err := it.client.Get(ctx, pageURL)
+	if err != nil {
+		return err
+	}
+
+	var page []T
+	if err := json.Unmarshal([]byte(data), &page); err != nil {
+		return fmt.Errorf("decoding page at offset %d of %s: %w", it.offset, it.endPoint, err)
+	}
+
+	it.page = page
+	it.pos = 0
+	it.offset += len(page)
+
+	// A short page is the last one. A page larger than requested means the
+	// endpoint ignores paging and returned everything at once
+	it.last = len(page) < size || len(page) > size
+
+	return nil
+}
+
+// Collect drains an Iterator and returns all of its entities
+func Collect[T any](ctx context.Context, it *Iterator[T]) ([]T, error) {
+	var items []T
+
+	for it.Next(ctx) {
+		items = append(items, it.Item())
+	}
+
+	return items, it.Err()
+}
+
+// ListEntity is a helper function which retrieves the entities of a list
+// endpoint across as many pages as needed, as raw JSON
+func (c *Client) ListEntity(ctx context.Context, endPoint string, opts ListOptions) ([]json.RawMessage, error) {
+	return Collect(ctx, Iterate[json.RawMessage](c, endPoint, opts))
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// pagingLedger serves total integers from 0 as a list endpoint, paged by the
+// size and offset query parameters unless it ignores paging, recording the
+// query of each request
+type pagingLedger struct {
+	total         int
+	ignoresPaging bool
+	queries       []string
+}
+
+func (l *pagingLedger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.queries = append(l.queries, r.URL.RawQuery)
+
+	size, offset := l.total, 0
+	if !l.ignoresPaging {
+		size, _ = strconv.Atoi(r.URL.Query().Get("size"))
+		offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	}
+
+	page := []int{}
+	for i := offset; i < l.total && i < offset+size; i++ {
+		page = append(page, i)
+	}
+
+	writeJSON(w, page)
+}
+
+// pageQuery returns the query of a page request without filters
+func pageQuery(offset int, size int) string {
+	return fmt.Sprintf("offset=%d&size=%d", offset, size)
+}
+
+func TestIterate(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         int
+		ignoresPaging bool
+		opts          ListOptions
+		want          int
+		wantQueries   []string
+	}{
+		{"stops on a short page", 25, false, ListOptions{Size: 10}, 25, []string{pageQuery(0, 10), pageQuery(10, 10), pageQuery(20, 10)}},
+		{"stops on an empty page", 20, false, ListOptions{Size: 10}, 20, []string{pageQuery(0, 10), pageQuery(10, 10), pageQuery(20, 10)}},
+		{"stops on an oversized page", 25, true, ListOptions{Size: 10}, 25, []string{pageQuery(0, 10)}},
+		{"default page size", 150, false, ListOptions{}, 150, []string{pageQuery(0, 100), pageQuery(100, 100)}},
+		{"limit shrinks the last page", 100, false, ListOptions{Size: 10, Limit: 25}, 25, []string{pageQuery(0, 10), pageQuery(10, 10), pageQuery(20, 5)}},
+		{"limit smaller than a page", 100, false, ListOptions{Size: 10, Limit: 3}, 3, []string{pageQuery(0, 3)}},
+		{"limit on a page boundary", 100, false, ListOptions{Size: 10, Limit: 20}, 20, []string{pageQuery(0, 10), pageQuery(10, 10)}},
+		{"limit of an endpoint ignoring paging", 25, true, ListOptions{Size: 10, Limit: 12}, 12, []string{pageQuery(0, 10)}},
+		{"limit beyond the last entity", 15, false, ListOptions{Size: 10, Limit: 50}, 15, []string{pageQuery(0, 10), pageQuery(10, 10)}},
+		{"offset", 25, false, ListOptions{Size: 10, Offset: 18}, 7, []string{pageQuery(18, 10)}},
+	}
+
+	for _, tt := range tests {
+		ledger := &pagingLedger{total: tt.total, ignoresPaging: tt.ignoresPaging}
+		client := newTestClient(t, ledger)
+
+		items, err := Collect(context.Background(), Iterate[int](client, "events", tt.opts))
+		if err != nil {
+			t.Errorf("%s: Collect = %v", tt.name, err)
+			continue
+		}
+
+		var want []int
+		for i := tt.opts.Offset; i < tt.opts.Offset+tt.want; i++ {
+			want = append(want, i)
+		}
+		if !slices.Equal(items, want) {
+			t.Errorf("%s: items = %v, want %v", tt.name, items, want)
+		}
+
+		if !slices.Equal(ledger.queries, tt.wantQueries) {
+			t.Errorf("%s: queries = %v, want %v", tt.name, ledger.queries, tt.wantQueries)
+		}
+	}
+}
+
+func TestIterateFilters(t *testing.T) {
+	ledger := &pagingLedger{total: 1}
+	client := newTestClient(t, ledger)
+
+	since := time.Date(2023, 11, 1, 9, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	before := time.Date(2023, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	if _, err := Collect(context.Background(), Iterate[int](client, "events?eventType=TRADE", ListOptions{Since: since, Before: before})); err != nil {
+		t.Fatalf("Collect = %v", err)
+	}
+
+	want := "before=2023-11-02T00%3A00%3A00Z&eventType=TRADE&offset=0&since=2023-11-01T14%3A30%3A00Z&size=100"
+	if len(ledger.queries) != 1 || ledger.queries[0] != want {
+		t.Errorf("queries = %v, want [%s]", ledger.queries, want)
+	}
+}
+
+func TestIterateError(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("offset") == "0" {
+			writeJSON(w, []int{1, 2})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	it := Iterate[int](client, "events", ListOptions{Size: 2})
+
+	var items []int
+	for it.Next(context.Background()) {
+		items = append(items, it.Item())
+	}
+
+	// The entities before the error are returned, and the Iterator stays done
+	if !slices.Equal(items, []int{1, 2}) || StatusCode(it.Err()) != http.StatusNotFound {
+		t.Errorf("items = %v, Err = %v, want [1 2] and a 404", items, it.Err())
+	}
+
+	if it.Next(context.Background()) || requests != 2 {
+		t.Errorf("Next after an error fetched again, %d requests", requests)
+	}
+}
@@ -0,0 +1,21 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+// Patch performs an HTTP PATCH operation on the 1Source REST API
+// It returns the response body and any error encountered; a non-2xx
+// response is returned as *APIError.
+func (c *Client) Patch(ctx context.Context, apiEndPoint string, body []byte) ([]byte, error) {
+	data, err := c.do(ctx, http.MethodPatch, apiEndPoint, body)
+
+	if err != nil {
+		log.Println("Error in HTTP PATCH API call: ", err)
+	}
+
+	return data, err
+}
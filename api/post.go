@@ -2,212 +2,248 @@
 package api
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"io"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"net/url"
-	"time"
 
 	"github.com/EquiLend/1Source-Go/models"
 )
 
-// PostProposeLoan will perform an HTTP POST operation
+// Post performs an HTTP POST operation on the 1Source REST API
+// It returns the response body and any error encountered; a non-2xx
+// response is returned as *APIError.
+func (c *Client) Post(ctx context.Context, apiEndPoint string, body []byte) ([]byte, error) {
+	data, err := c.do(ctx, http.MethodPost, apiEndPoint, body)
+
+	if err != nil {
+		log.Println("Error in HTTP POST API call: ", err)
+	}
+
+	return data, err
+}
+
+// ProposeLoan will perform an HTTP POST operation
 // against the 1Source REST API to propose a loan
-// https://www.kirandev.com/http-post-golang
-func PostProposeLoan(apiEndPoint string, bearer string, body []byte) (string, error) {
-	ctx := context.Background()
-	transport := &http.Transport{
-		Proxy: func(r *http.Request) (*url.URL, error) {
-			r.Header.Set("Authorization", bearer)
-			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-			return nil, nil
-		},
+func (c *Client) ProposeLoan(ctx context.Context, proposal models.LoanProposal) (string, error) {
+	if err := proposal.Validate(); err != nil {
+		return "", err
 	}
 
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(15) * time.Second,
+	body, err := json.Marshal(proposal)
+	if err != nil {
+		return "", fmt.Errorf("encoding loan proposal: %w", err)
 	}
 
-	request, err := http.NewRequestWithContext(ctx, "POST", apiEndPoint, bytes.NewBuffer(body))
-	request.Header.Set("Authorization", bearer)
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	respBody, err := c.Post(ctx, c.cfg.Endpoints.Loans, body)
 
 	if err != nil {
-		log.Println("Error creating new HTTP Request: ", err)
-		return "", err
+		return "", fmt.Errorf("proposing loan: %w", err)
 	}
 
-	log.Println("Calling API endpoint: ", apiEndPoint)
-	resp, err := client.Do(request)
+	var cir models.LoanInitiationResponse
 
-	// Close response body
-	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}()
+	err = json.Unmarshal(respBody, &cir)
+	if err != nil {
+		return "", fmt.Errorf("decoding loan proposal response: %w", err)
+	}
+
+	return cir.Message, nil
+}
 
+// CancelLoan will perform an HTTP POST operation
+// against the 1Source REST API to cancel a loan
+// The loan must be in a state from which it can be canceled.
+func (c *Client) CancelLoan(ctx context.Context, loanId string) (string, error) {
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionCancel)
 	if err != nil {
-		log.Fatal("Error in HTTP POST API call: ", err)
 		return "", err
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	if err := c.checkProposer("loan", loanId, loan.LastUpdatePartyId, string(models.LoanActionCancel), true); err != nil {
+		return "", err
+	}
+
+	respBody, err := c.Post(ctx, c.cfg.Endpoints.Loans+"/"+loanId+"/cancel", nil)
 
 	if err != nil {
-		log.Fatal("Error reading HTTP POST response: ", err)
+		return "", fmt.Errorf("canceling loan [%s]: %w", loanId, err)
+	}
+
+	var ccr models.LoanCancelReponse
+
+	err = json.Unmarshal(respBody, &ccr)
+	if err != nil {
+		return "", fmt.Errorf("decoding loan cancel response: %w", err)
+	}
+
+	return ccr.Message, nil
+}
+
+// DeclineLoan will perform an HTTP POST operation
+// against the 1Source REST API to decline a loan
+// The loan must be in a state from which it can be declined.
+func (c *Client) DeclineLoan(ctx context.Context, loanId string) (string, error) {
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionDecline)
+	if err != nil {
 		return "", err
 	}
 
-	if resp.StatusCode != http.StatusCreated {
-		log.Println("Error proposing loan. HTTP Response Status:", resp.Status)
-	} else {
-		var cir models.LoanInitiationResponse
+	if err := c.checkProposer("loan", loanId, loan.LastUpdatePartyId, string(models.LoanActionDecline), false); err != nil {
+		return "", err
+	}
 
-		err := json.Unmarshal(respBody, &cir)
-		if err != nil {
-			return "", err
-		}
+	respBody, err := c.Post(ctx, c.cfg.Endpoints.Loans+"/"+loanId+"/decline", nil)
 
-		return cir.Message, err
+	if err != nil {
+		return "", fmt.Errorf("declining loan [%s]: %w", loanId, err)
 	}
 
-	return "", err
-}
+	var cdr models.LoanDeclineReponse
 
-// PostCancelLoan will perform an HTTP POST operation
-// against the 1Source REST API to cancel a loan
-// https://www.kirandev.com/http-post-golang
-func PostCancelLoan(apiEndPoint string, bearer string) (string, error) {
-	ctx := context.Background()
-	transport := &http.Transport{
-		Proxy: func(r *http.Request) (*url.URL, error) {
-			r.Header.Set("Authorization", bearer)
-			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-			return nil, nil
-		},
+	err = json.Unmarshal(respBody, &cdr)
+	if err != nil {
+		return "", fmt.Errorf("decoding loan decline response: %w", err)
 	}
 
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(15) * time.Second,
+	return cdr.Message, nil
+}
+
+// ApproveLoan will perform an HTTP POST operation against the 1Source REST
+// API to approve a proposed loan as the counterparty of its proposer
+// The loan must be PROPOSED and the configured party must be one of its
+// transacting parties other than the proposer. The party role of the
+// settlement instruction is filled in from the loan when left empty.
+// It returns the loan as it is after the approval.
+func (c *Client) ApproveLoan(ctx context.Context, loanId string, approval models.LoanProposalApproval) (*models.Loan, error) {
+	if err := approval.Settlement.Instruction.Validate(); err != nil {
+		return nil, err
 	}
 
-	request, err := http.NewRequestWithContext(ctx, "POST", apiEndPoint, nil)
-	request.Header.Set("Authorization", bearer)
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionApprove)
+	if err != nil {
+		return nil, err
+	}
 
+	role, err := c.partyRole(loan)
 	if err != nil {
-		log.Println("Error creating new HTTP Request: ", err)
-		return "", err
+		return nil, err
 	}
 
-	log.Println("Calling API endpoint: ", apiEndPoint)
-	resp, err := client.Do(request)
+	if err := c.checkProposer("loan", loanId, loan.LastUpdatePartyId, string(models.LoanActionApprove), false); err != nil {
+		return nil, err
+	}
 
-	// Close response body
-	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}()
+	if approval.Settlement.PartyRole == "" {
+		approval.Settlement.PartyRole = role
+	} else if approval.Settlement.PartyRole != role {
+		return nil, fmt.Errorf("settlement instruction is for %s but party [%s] is the %s of loan [%s]", approval.Settlement.PartyRole, c.cfg.General.Party_Id, role, loanId)
+	}
 
+	body, err := json.Marshal(approval)
 	if err != nil {
-		log.Fatal("Error in HTTP POST API call: ", err)
-		return "", err
+		return nil, fmt.Errorf("encoding loan approval: %w", err)
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	if _, err := c.Post(ctx, c.cfg.Endpoints.Loans+"/"+loanId+"/approve", body); err != nil {
+		return nil, fmt.Errorf("approving loan [%s]: %w", loanId, err)
+	}
 
+	return c.GetLoan(ctx, loanId)
+}
+
+// loanFor retrieves a loan and checks the action against the loan state
+// machine, so that the ledger is only called for actions the loan's current
+// status allows
+func (c *Client) loanFor(ctx context.Context, loanId string, action models.LoanAction) (*models.Loan, error) {
+	loan, err := c.GetLoan(ctx, loanId)
 	if err != nil {
-		log.Fatal("Error reading HTTP POST response: ", err)
-		return "", err
+		return nil, fmt.Errorf("retrieving loan [%s]: %w", loanId, err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Println("Error canceling proposed loan. HTTP Response Status:", resp.Status)
-	} else {
-		var ccr models.LoanCancelReponse
+	if err := loan.CheckAction(action); err != nil {
+		return nil, err
+	}
 
-		err := json.Unmarshal(respBody, &ccr)
-		if err != nil {
-			return "", err
-		}
+	return loan, nil
+}
 
-		return ccr.Message, err
+// errPartyId is returned by operations which need to know which side of a
+// loan the configured party is on when party_id is not configured
+var errPartyId = errors.New("party_id is not set in the [general] section of the configuration TOML file")
+
+// partyRole returns the role of the configured party in a loan
+func (c *Client) partyRole(loan *models.Loan) (models.PartyRole, error) {
+	partyId := c.cfg.General.Party_Id
+	if partyId == "" {
+		return "", errPartyId
+	}
+
+	role, ok := loan.Trade.PartyRole(partyId)
+	if !ok {
+		return "", fmt.Errorf("party [%s] is not a transacting party of loan [%s]", partyId, loan.LoanId)
 	}
 
-	return "", err
+	return role, nil
 }
 
-// PostDeclineLoan will perform an HTTP POST operation
-// against the 1Source REST API to decline a loan
-// https://www.kirandev.com/http-post-golang
-func PostDeclineLoan(apiEndPoint string, bearer string) (string, error) {
-	ctx := context.Background()
-	transport := &http.Transport{
-		Proxy: func(r *http.Request) (*url.URL, error) {
-			r.Header.Set("Authorization", bearer)
-			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-			return nil, nil
-		},
+// checkProposer checks that the configured party is, with byProposer, or
+// is not the party which proposed a loan or rerate, so that only the
+// proposer cancels a proposal and only its counterparty approves or
+// declines it. An empty proposer is unknown and refused rather than guessed
+func (c *Client) checkProposer(entity string, id string, proposer string, action string, byProposer bool) error {
+	partyId := c.cfg.General.Party_Id
+	if partyId == "" {
+		return errPartyId
 	}
 
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(15) * time.Second,
+	if proposer == "" {
+		return fmt.Errorf("the proposer of %s [%s] is unknown, so it cannot be checked that party [%s] may %s it", entity, id, partyId, action)
 	}
 
-	request, err := http.NewRequestWithContext(ctx, "POST", apiEndPoint, nil)
-	request.Header.Set("Authorization", bearer)
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if byProposer && proposer != partyId {
+		return fmt.Errorf("%s [%s] was proposed by the counterparty [%s]; only its proposer can %s it, the counterparty can decline it instead", entity, id, proposer, action)
+	}
 
-	if err != nil {
-		log.Println("Error creating new HTTP Request: ", err)
-		return "", err
+	if !byProposer && proposer == partyId {
+		return fmt.Errorf("%s [%s] was proposed by party [%s] and can only be %s by its counterparty; cancel it instead", entity, id, partyId, pastTense(action))
 	}
 
-	log.Println("Calling API endpoint: ", apiEndPoint)
-	resp, err := client.Do(request)
+	return nil
+}
+
+// postJSON encodes body as JSON, POSTs it to the 1Source REST API and
+// returns the message of the ledger response. A nil body sends no content
+func (c *Client) postJSON(ctx context.Context, endPoint string, body any, action string) (string, error) {
+	var data []byte
 
-	// Close response body
-	defer func() {
-		err := resp.Body.Close()
+	if body != nil {
+		var err error
+
+		data, err = json.Marshal(body)
 		if err != nil {
-			log.Fatal(err)
+			return "", fmt.Errorf("encoding request for %s: %w", action, err)
 		}
-	}()
-
-	if err != nil {
-		log.Fatal("Error in HTTP POST API call: ", err)
-		return "", err
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
-
+	respBody, err := c.Post(ctx, endPoint, data)
 	if err != nil {
-		log.Fatal("Error reading HTTP POST response: ", err)
-		return "", err
+		return "", fmt.Errorf("%s: %w", action, err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Println("Error declining proposed loan. HTTP Response Status:", resp.Status)
-	} else {
-		var cdr models.LoanDeclineReponse
+	return ledgerMessage(respBody), nil
+}
 
-		err := json.Unmarshal(respBody, &cdr)
-		if err != nil {
-			return "", err
-		}
+// ledgerMessage returns the message of a ledger response body, or the body
+// itself if it is not a ledger response
+func ledgerMessage(body []byte) string {
+	var lr models.LedgerResponse
 
-		return cdr.Message, err
+	if err := json.Unmarshal(body, &lr); err != nil || lr.Message == "" {
+		return string(body)
 	}
 
-	return "", err
+	return lr.Message
 }
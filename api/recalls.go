@@ -0,0 +1,103 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// ProposeRecall will perform an HTTP POST operation against the 1Source REST
+// API to recall securities on an OPEN loan as its lender
+// The quantity must not exceed the loan's open quantity and the due date
+// must not be before the recall date.
+func (c *Client) ProposeRecall(ctx context.Context, loanId string, proposal models.RecallProposal) (string, error) {
+	if proposal.Quantity <= 0 {
+		return "", errors.New("recall quantity must be positive")
+	}
+
+	if proposal.RecallDueDate < proposal.RecallDate {
+		return "", fmt.Errorf("recall due date %s is before the recall date %s", proposal.RecallDueDate, proposal.RecallDate)
+	}
+
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionRecall)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.requireRole(loan, models.PartyRoleLender, "recall"); err != nil {
+		return "", err
+	}
+
+	open := loan.Trade.Open()
+
+	if proposal.Quantity > open {
+		return "", fmt.Errorf("recall quantity %d exceeds the open quantity %d of loan [%s]", proposal.Quantity, open, loanId)
+	}
+
+	return c.postJSON(ctx, c.cfg.Endpoints.Loans+"/"+loanId+"/recalls", proposal, "proposing recall of loan ["+loanId+"]")
+}
+
+// CancelRecall will perform an HTTP POST operation against the 1Source REST
+// API to cancel an OPEN recall as the lender of the loan
+func (c *Client) CancelRecall(ctx context.Context, recallId string) (string, error) {
+	recall, err := c.GetRecall(ctx, recallId)
+	if err != nil {
+		return "", fmt.Errorf("retrieving recall [%s]: %w", recallId, err)
+	}
+
+	if recall.Status != models.RecallStatusOpen {
+		return "", fmt.Errorf("recall [%s] is in %s state, not OPEN, and cannot be canceled", recallId, recall.Status)
+	}
+
+	loan, err := c.GetLoan(ctx, recall.LoanId)
+	if err != nil {
+		return "", fmt.Errorf("retrieving loan [%s] of recall [%s]: %w", recall.LoanId, recallId, err)
+	}
+
+	if err := loan.CheckAction(models.LoanActionRecall); err != nil {
+		return "", err
+	}
+
+	if err := c.requireRole(loan, models.PartyRoleLender, "cancel recalls of"); err != nil {
+		return "", err
+	}
+
+	endPoint := c.cfg.Endpoints.Loans + "/" + recall.LoanId + "/recalls/" + recallId + "/cancel"
+
+	return c.postJSON(ctx, endPoint, nil, "canceling recall ["+recallId+"]")
+}
+
+// ListOpenRecalls retrieves the OPEN recalls the user is authorized to view,
+// only those of the given loan_id when it is not empty
+func (c *Client) ListOpenRecalls(ctx context.Context, loanId string) (models.Recalls, error) {
+	recalls, err := c.ListRecalls(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var open models.Recalls
+	for _, r := range recalls {
+		if r.Status == models.RecallStatusOpen && (loanId == "" || r.LoanId == loanId) {
+			open = append(open, r)
+		}
+	}
+
+	return open, nil
+}
+
+// requireRole checks that the configured party has the given role in a loan
+func (c *Client) requireRole(loan *models.Loan, want models.PartyRole, action string) error {
+	role, err := c.partyRole(loan)
+	if err != nil {
+		return err
+	}
+
+	if role != want {
+		return fmt.Errorf("party [%s] is the %s of loan [%s]; only the %s can %s it", c.cfg.General.Party_Id, role, loan.LoanId, want, action)
+	}
+
+	return nil
+}
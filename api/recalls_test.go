@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+func TestProposeRecallOpenQuantity(t *testing.T) {
+	zero, partial := int64(0), int64(4000)
+
+	tests := []struct {
+		name     string
+		open     *int64
+		quantity int64
+		wantErr  string
+	}{
+		{"whole loan open", nil, 10000, ""},
+		{"more than the loan", nil, 10001, "exceeds the open quantity 10000"},
+		{"partly returned", &partial, 4000, ""},
+		{"more than is left", &partial, 4001, "exceeds the open quantity 4000"},
+		{"fully returned", &zero, 1, "exceeds the open quantity 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := testLoan("L1")
+			loan.Trade.OpenQuantity = tt.open
+			posted := false
+
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/v1/ledger/loans/L1/recalls":
+					posted = true
+					writeJSON(w, models.LedgerResponse{Message: "recall proposed"})
+				case r.URL.Path == "/v1/ledger/loans/L1":
+					writeJSON(w, loan)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			proposal := models.RecallProposal{Quantity: tt.quantity, RecallDate: "2023-11-01", RecallDueDate: "2023-11-03"}
+			_, err := client.ProposeRecall(context.Background(), "L1", proposal)
+
+			if tt.wantErr == "" && (err != nil || !posted) {
+				t.Errorf("ProposeRecall = %v, posted %v, want the recall proposed", err, posted)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr) || posted) {
+				t.Errorf("ProposeRecall = %v, posted %v, want an error containing %q", err, posted, tt.wantErr)
+			}
+		})
+	}
+}
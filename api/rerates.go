@@ -0,0 +1,113 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// ProposeRerate will perform an HTTP POST operation against the 1Source REST
+// API to propose a new rate for an OPEN loan
+func (c *Client) ProposeRerate(ctx context.Context, loanId string, proposal models.RerateProposal) (string, error) {
+	if err := proposal.Rerate.Validate(); err != nil {
+		return "", err
+	}
+
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionRerate)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := c.partyRole(loan); err != nil {
+		return "", err
+	}
+
+	return c.postJSON(ctx, c.cfg.Endpoints.Loans+"/"+loanId+"/rerates", proposal, "proposing rerate of loan ["+loanId+"]")
+}
+
+// ApproveRerate will perform an HTTP POST operation against the 1Source REST
+// API to approve a rerate proposed by the counterparty
+func (c *Client) ApproveRerate(ctx context.Context, rerateId string) (string, error) {
+	return c.rerateAction(ctx, rerateId, "approve", false)
+}
+
+// DeclineRerate will perform an HTTP POST operation against the 1Source REST
+// API to decline a rerate proposed by the counterparty
+func (c *Client) DeclineRerate(ctx context.Context, rerateId string) (string, error) {
+	return c.rerateAction(ctx, rerateId, "decline", false)
+}
+
+// CancelRerate will perform an HTTP POST operation against the 1Source REST
+// API to cancel a rerate we proposed
+func (c *Client) CancelRerate(ctx context.Context, rerateId string) (string, error) {
+	return c.rerateAction(ctx, rerateId, "cancel", true)
+}
+
+// rerateAction checks that a rerate is PROPOSED, that its loan can still be
+// rerated and, when its proposer can be found, that the configured party is
+// (byProposer) or is not its proposer, then POSTs the action
+func (c *Client) rerateAction(ctx context.Context, rerateId string, action string, byProposer bool) (string, error) {
+	rerate, err := getOne[models.Rerate](ctx, c, c.cfg.Endpoints.Rerates+"/"+rerateId)
+	if err != nil {
+		return "", fmt.Errorf("retrieving rerate [%s]: %w", rerateId, err)
+	}
+
+	if rerate.Status != models.RerateStatusProposed {
+		return "", fmt.Errorf("rerate [%s] is in %s state, not PROPOSED, and cannot be %s", rerateId, rerate.Status, pastTense(action))
+	}
+
+	if _, err := c.loanFor(ctx, rerate.LoanId, models.LoanActionRerate); err != nil {
+		return "", err
+	}
+
+	// The ledger refuses the action of the wrong party anyway, so a proposer
+	// which cannot be found only skips the check made here
+	if proposer := c.rerateProposer(ctx, rerate); proposer == "" {
+		log.Printf("Proposer of rerate [%s] not found, leaving the check of party [%s] to 1Source", rerateId, c.cfg.General.Party_Id)
+	} else if err := c.checkProposer("rerate", rerateId, proposer, action, byProposer); err != nil {
+		return "", err
+	}
+
+	endPoint := c.cfg.Endpoints.Loans + "/" + rerate.LoanId + "/rerates/" + rerateId + "/" + action
+
+	return c.postJSON(ctx, endPoint, nil, action+" rerate ["+rerateId+"]")
+}
+
+// rerateProposer returns the party which proposed a PROPOSED rerate. The
+// rerate itself does not tell it, but its last event is the RERATE_PROPOSED
+// event, and the version of its loan made by that event was last updated by
+// the proposer. It returns "" if the loan's history has no such version
+func (c *Client) rerateProposer(ctx context.Context, rerate *models.Rerate) string {
+	if rerate.LastEventId == 0 {
+		return ""
+	}
+
+	history, err := c.GetLoanHistory(ctx, rerate.LoanId)
+	if err != nil {
+		log.Printf("Error retrieving history of loan [%s]: %s", rerate.LoanId, err)
+		return ""
+	}
+
+	for _, loan := range history {
+		if loan.LastEventId == rerate.LastEventId {
+			return loan.LastUpdatePartyId
+		}
+	}
+
+	return ""
+}
+
+// pastTense returns the past tense of a lifecycle action, for messages
+func pastTense(action string) string {
+	switch action {
+	case "cancel":
+		return "canceled"
+	case "submit":
+		return "submitted"
+	}
+
+	return action + "d"
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+func TestRerateAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		proposer string
+		action   func(c *Client, ctx context.Context, rerateId string) (string, error)
+		path     string
+		wantErr  string
+	}{
+		{"counterparty's rerate approved", "TBORR-US", (*Client).ApproveRerate, "approve", ""},
+		{"counterparty's rerate declined", "TBORR-US", (*Client).DeclineRerate, "decline", ""},
+		{"counterparty's rerate not canceled", "TBORR-US", (*Client).CancelRerate, "", "only its proposer can cancel it"},
+		{"own rerate canceled", testPartyId, (*Client).CancelRerate, "cancel", ""},
+		{"own rerate not approved", testPartyId, (*Client).ApproveRerate, "", "can only be approved by its counterparty"},
+		{"own rerate not declined", testPartyId, (*Client).DeclineRerate, "", "can only be declined by its counterparty"},
+		{"unknown proposer approves", "", (*Client).ApproveRerate, "approve", ""},
+		{"unknown proposer declines", "", (*Client).DeclineRerate, "decline", ""},
+		{"unknown proposer cancels", "", (*Client).CancelRerate, "cancel", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var posted []string
+
+			// The loan's history has the version made by the rerate's
+			// RERATE_PROPOSED event only when the proposer is known
+			history := models.Loans{testLoan("L1")}
+			history[0].LastEventId = 41
+			if tt.proposer != "" {
+				proposed := testLoan("L1")
+				proposed.LastEventId = 42
+				proposed.LastUpdatePartyId = tt.proposer
+				history = append(history, proposed)
+			}
+
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost:
+					mu.Lock()
+					posted = append(posted, r.URL.Path)
+					mu.Unlock()
+					writeJSON(w, models.LedgerResponse{Message: "done"})
+				case r.URL.Path == "/v1/ledger/rerates/R1":
+					writeJSON(w, models.Rerate{RerateId: "R1", LoanId: "L1", Status: models.RerateStatusProposed, LastEventId: 42})
+				case r.URL.Path == "/v1/ledger/loans/L1":
+					writeJSON(w, testLoan("L1"))
+				case r.URL.Path == "/v1/ledger/loans/L1/history":
+					writeJSON(w, history)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			_, err := tt.action(client, context.Background(), "R1")
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				if len(posted) != 0 {
+					t.Errorf("posted %v, want nothing", posted)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			want := "/v1/ledger/loans/L1/rerates/R1/" + tt.path
+			if len(posted) != 1 || posted[0] != want {
+				t.Errorf("posted %v, want %s", posted, want)
+			}
+		})
+	}
+}
+
+func TestRerateActionNotProposed(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			t.Errorf("unexpected POST %s", r.URL.Path)
+		}
+		writeJSON(w, models.Rerate{RerateId: "R1", LoanId: "L1", Status: models.RerateStatusApproved})
+	}))
+
+	_, err := client.ApproveRerate(context.Background(), "R1")
+	if err == nil || !strings.Contains(err.Error(), "not PROPOSED") {
+		t.Errorf("error = %v, want the rerate not PROPOSED", err)
+	}
+}
@@ -0,0 +1,181 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Default retry settings, used when the [retry] section of the
+// configuration TOML file leaves a value unset
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 500 * time.Millisecond
+	DefaultMaxDelay    = 30 * time.Second
+)
+
+// DefaultRetryStatusCodes are the HTTP status codes retried by default
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy controls how a Client retries failed calls to the 1Source
+// REST API. Delays grow exponentially from BaseDelay up to MaxDelay with
+// full jitter. A Retry-After header sent by the server takes precedence, up
+// to MaxDelay. Only GET requests are retried unless RetryPost is set
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	StatusCodes []int
+	RetryPost   bool
+}
+
+// NewRetryPolicy creates a RetryPolicy from the [retry] section of the
+// configuration TOML file
+func NewRetryPolicy(cfg *models.AppConfig) RetryPolicy {
+	retry := cfg.Retry
+
+	policy := RetryPolicy{
+		MaxAttempts: count(retry.Max_Attempts, DefaultMaxAttempts),
+		BaseDelay:   milliseconds(retry.Base_Delay_Ms, DefaultBaseDelay),
+		MaxDelay:    milliseconds(retry.Max_Delay_Ms, DefaultMaxDelay),
+		StatusCodes: retry.Status_Codes,
+	}
+
+	if len(policy.StatusCodes) == 0 {
+		policy.StatusCodes = DefaultRetryStatusCodes
+	}
+
+	return policy
+}
+
+// attempts returns how many times a request with the given method may be sent
+func (p RetryPolicy) attempts(method string) int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return p.MaxAttempts
+	case http.MethodPost:
+		if p.RetryPost {
+			return p.MaxAttempts
+		}
+	}
+
+	return 1
+}
+
+// retryable reports whether a failed call is worth retrying: either the
+// server answered with one of the configured status codes, or the request
+// failed at the network level
+func (p RetryPolicy) retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return slices.Contains(p.StatusCodes, apiErr.StatusCode)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// RetryAfterError is an error which carries how long the server asked to
+// wait before retrying, such as from a Retry-After header, or 0
+type RetryAfterError interface {
+	error
+	RetryAfterDelay() time.Duration
+}
+
+// Delay returns how long to wait before the next attempt, after the given
+// number of failed attempts. A RetryAfterError in err's chain takes
+// precedence over the backoff, but is capped at MaxDelay so that a server
+// asking for a long wait cannot stall the caller for longer
+func (p RetryPolicy) Delay(attempt int, err error) time.Duration {
+	var retryAfter RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.RetryAfterDelay() > 0 {
+		if p.MaxDelay > 0 {
+			return min(retryAfter.RetryAfterDelay(), p.MaxDelay)
+		}
+		return retryAfter.RetryAfterDelay()
+	}
+
+	backoff := p.BaseDelay << (attempt - 1)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	// Full jitter spreads out retries from many concurrent callers
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// Wait sleeps for the Delay before the next attempt, after the given number
+// of failed attempts with err. It is shared by the retry loops of the Client
+// and those outside it, such as of event handlers and webhooks, and returns
+// ctx's error if ctx is done first
+func (p RetryPolicy) Wait(ctx context.Context, attempt int, err error) error {
+	timer := time.NewTimer(p.Delay(attempt, err))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ParseRetryAfter decodes a Retry-After header, given either in seconds or
+// as an HTTP date. It returns 0 when the header is absent or invalid
+func ParseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// milliseconds converts a number of milliseconds from the configuration
+// TOML file into a time.Duration, falling back to def when it is unset
+func milliseconds(value uint32, def time.Duration) time.Duration {
+	if value == 0 {
+		return def
+	}
+
+	return time.Duration(value) * time.Millisecond
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryAttempts(t *testing.T) {
+	tests := []struct {
+		policy RetryPolicy
+		method string
+		want   int
+	}{
+		{RetryPolicy{MaxAttempts: 3}, http.MethodGet, 3},
+		{RetryPolicy{MaxAttempts: 3}, http.MethodHead, 3},
+		{RetryPolicy{MaxAttempts: 3}, http.MethodPost, 1},
+		{RetryPolicy{MaxAttempts: 3, RetryPost: true}, http.MethodPost, 3},
+		{RetryPolicy{MaxAttempts: 3, RetryPost: true}, http.MethodPatch, 1},
+		{RetryPolicy{MaxAttempts: 3, RetryPost: true}, http.MethodPut, 1},
+		{RetryPolicy{MaxAttempts: 0}, http.MethodGet, 1},
+		{RetryPolicy{MaxAttempts: -1, RetryPost: true}, http.MethodPost, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.attempts(tt.method); got != tt.want {
+			t.Errorf("attempts(%s) with %+v = %d, want %d", tt.method, tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	policy := RetryPolicy{StatusCodes: DefaultRetryStatusCodes}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"429", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"502", &APIError{StatusCode: http.StatusBadGateway}, true},
+		{"503", &APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"504", &APIError{StatusCode: http.StatusGatewayTimeout}, true},
+		{"wrapped 503", fmt.Errorf("listing loans: %w", &APIError{StatusCode: http.StatusServiceUnavailable}), true},
+		{"400", &APIError{StatusCode: http.StatusBadRequest}, false},
+		{"404", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"500", &APIError{StatusCode: http.StatusInternalServerError}, false},
+		{"network error", &url.Error{Op: "Get", URL: "http://ledger", Err: errors.New("connection refused")}, true},
+		{"canceled", &url.Error{Op: "Get", URL: "http://ledger", Err: context.Canceled}, false},
+		{"other error", errors.New("invalid endpoint"), false},
+	}
+
+	for _, tt := range tests {
+		if got := policy.retryable(tt.err); got != tt.want {
+			t.Errorf("retryable(%s) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	// Only the configured status codes are retried
+	policy = RetryPolicy{StatusCodes: []int{http.StatusInternalServerError}}
+	if !policy.retryable(&APIError{StatusCode: http.StatusInternalServerError}) || policy.retryable(&APIError{StatusCode: http.StatusServiceUnavailable}) {
+		t.Errorf("retryable does not follow StatusCodes %v", policy.StatusCodes)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"", 0, 0},
+		{"0", 0, 0},
+		{"5", 5 * time.Second, 5 * time.Second},
+		{" 120 ", 2 * time.Minute, 2 * time.Minute},
+		{"-5", 0, 0},
+		{"soon", 0, 0},
+		{"1.5", 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("ParseRetryAfter(%q) = %s, want between %s and %s", tt.value, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	// The backoff doubles with each attempt, with jitter, up to MaxDelay
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 80: time.Second} {
+		for i := 0; i < 20; i++ {
+			if got := policy.Delay(attempt, errors.New("failed")); got < 0 || got > max {
+				t.Errorf("Delay(%d) = %s, want at most %s", attempt, got, max)
+			}
+		}
+	}
+
+	// A Retry-After takes precedence over the backoff, up to MaxDelay
+	tests := []struct {
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{500 * time.Millisecond, 500 * time.Millisecond},
+		{time.Hour, time.Second},
+	}
+
+	for _, tt := range tests {
+		err := fmt.Errorf("getting loan: %w", &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: tt.retryAfter})
+		if got := policy.Delay(1, err); got != tt.want {
+			t.Errorf("Delay with a Retry-After of %s = %s, want %s", tt.retryAfter, got, tt.want)
+		}
+	}
+
+	if got := (RetryPolicy{}).Delay(1, &APIError{RetryAfter: time.Minute}); got != time.Minute {
+		t.Errorf("Delay without a MaxDelay = %s, want the Retry-After", got)
+	}
+}
+
+// countingLedger answers each request with the next of statuses, and 200
+// once they run out, counting the requests by method
+type countingLedger struct {
+	mu         sync.Mutex
+	statuses   []int
+	retryAfter string
+	requests   map[string]int
+}
+
+func (l *countingLedger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.requests == nil {
+		l.requests = map[string]int{}
+	}
+	l.requests[r.Method]++
+
+	if len(l.statuses) == 0 {
+		writeJSON(w, map[string]string{"message": "ok"})
+		return
+	}
+
+	status := l.statuses[0]
+	l.statuses = l.statuses[1:]
+
+	if l.retryAfter != "" {
+		w.Header().Set("Retry-After", l.retryAfter)
+	}
+	w.WriteHeader(status)
+}
+
+func TestClientRetries(t *testing.T) {
+	unavailable := http.StatusServiceUnavailable
+
+	tests := []struct {
+		name         string
+		method       string
+		retryPost    bool
+		statuses     []int
+		wantStatus   int
+		wantRequests int
+	}{
+		{"GET retried until it succeeds", http.MethodGet, false, []int{unavailable, http.StatusBadGateway}, 0, 3},
+		{"GET retried up to MaxAttempts", http.MethodGet, false, []int{unavailable, unavailable, unavailable, unavailable}, unavailable, 3},
+		{"GET not retried on a 400", http.MethodGet, false, []int{http.StatusBadRequest}, http.StatusBadRequest, 1},
+		{"GET not retried on a 500", http.MethodGet, false, []int{http.StatusInternalServerError}, http.StatusInternalServerError, 1},
+		{"POST not retried", http.MethodPost, false, []int{unavailable}, unavailable, 1},
+		{"POST retried with RetryPost", http.MethodPost, true, []int{unavailable, http.StatusTooManyRequests}, 0, 3},
+		{"PATCH not retried with RetryPost", http.MethodPatch, true, []int{unavailable}, unavailable, 1},
+	}
+
+	for _, tt := range tests {
+		ledger := &countingLedger{statuses: tt.statuses}
+		client := newTestClient(t, ledger)
+
+		policy := client.RetryPolicy()
+		policy.RetryPost = tt.retryPost
+		client = client.WithRetryPolicy(policy)
+
+		var body []byte
+		if tt.method != http.MethodGet {
+			body = []byte("{}")
+		}
+
+		_, err := client.do(context.Background(), tt.method, "loans", body)
+		if got := StatusCode(err); got != tt.wantStatus {
+			t.Errorf("%s: do = %v, want status %d", tt.name, err, tt.wantStatus)
+		}
+
+		if got := ledger.requests[tt.method]; got != tt.wantRequests {
+			t.Errorf("%s: %d requests, want %d", tt.name, got, tt.wantRequests)
+		}
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{"seconds", "7", 7 * time.Second},
+		{"HTTP date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), time.Hour},
+		{"invalid", "later", 0},
+	}
+
+	for _, tt := range tests {
+		ledger := &countingLedger{statuses: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests}, retryAfter: tt.retryAfter}
+		client := newTestClient(t, ledger)
+
+		// The Retry-After is honoured only up to MaxDelay, so the retries
+		// do not hold up the test
+		start := time.Now()
+		_, err := client.do(context.Background(), http.MethodGet, "loans", nil)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: retries took %s, want them capped at MaxDelay", tt.name, elapsed)
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("%s: do = %v, want a 429", tt.name, err)
+		}
+
+		if got := apiErr.RetryAfter; got > tt.want || got < tt.want-5*time.Second {
+			t.Errorf("%s: RetryAfter = %s, want %s", tt.name, got, tt.want)
+		}
+
+		if ledger.requests[http.MethodGet] != 3 {
+			t.Errorf("%s: %d requests, want 3", tt.name, ledger.requests[http.MethodGet])
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// ListLoanReturns retrieves the returns of a loan. 1Source has no list of
+// the returns of a loan, so the returns the user is authorized to view are
+// filtered by loan_id
+func (c *Client) ListLoanReturns(ctx context.Context, loanId string, opts ListOptions) (models.Returns, error) {
+	returns, err := c.ListReturns(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var loanReturns models.Returns
+	for _, r := range returns {
+		if r.LoanId == loanId {
+			loanReturns = append(loanReturns, r)
+		}
+	}
+
+	return loanReturns, nil
+}
+
+// returnsSince returns the time from which to list the returns of a loan,
+// so that the returns of older loans are not fetched. That is the day before
+// its trade date, so that no time zone leaves out a return made on the trade
+// date, or the zero time when the trade date is missing or invalid
+func returnsSince(loan *models.Loan) time.Time {
+	tradeDate, err := time.Parse(time.DateOnly, loan.Trade.TradeDate)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return tradeDate.AddDate(0, 0, -1)
+}
+
+// OpenQuantity returns the quantity of a loan which is still available to
+// return: the loan's open quantity less the quantity of its returns which are
+// pending or acknowledged but not yet settled. The return with the given
+// return_id, if any, is left out so that it can be checked against the rest.
+// Only the returns since the loan's trade date are retrieved
+func (c *Client) OpenQuantity(ctx context.Context, loan *models.Loan, excludeReturnId string) (int64, error) {
+	open := loan.Trade.Open()
+
+	returns, err := c.ListLoanReturns(ctx, loan.LoanId, ListOptions{Since: returnsSince(loan)})
+	if err != nil {
+		return 0, fmt.Errorf("retrieving returns of loan [%s]: %w", loan.LoanId, err)
+	}
+
+	for _, r := range returns {
+		if r.ReturnId == excludeReturnId {
+			continue
+		}
+
+		if r.Status == models.ReturnStatusPending || r.Status == models.ReturnStatusAcknowledged {
+			open -= r.Quantity
+		}
+	}
+
+	return open, nil
+}
+
+// ProposeReturn will perform an HTTP POST operation against the 1Source REST
+// API to propose a return of securities on an OPEN loan
+// The quantity must not exceed the loan's open quantity. The party role of
+// each settlement instruction is filled in from the loan when left empty.
+func (c *Client) ProposeReturn(ctx context.Context, loanId string, proposal models.ReturnProposal) (string, error) {
+	if proposal.Quantity <= 0 {
+		return "", errors.New("return quantity must be positive")
+	}
+
+	for i := range proposal.Settlement {
+		if err := proposal.Settlement[i].Instruction.Validate(); err != nil {
+			return "", err
+		}
+	}
+
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionReturn)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.checkReturnQuantity(ctx, loan, proposal.Quantity, ""); err != nil {
+		return "", err
+	}
+
+	for i := range proposal.Settlement {
+		if proposal.Settlement[i].PartyRole == "" {
+			role, err := c.partyRole(loan)
+			if err != nil {
+				return "", err
+			}
+			proposal.Settlement[i].PartyRole = role
+		}
+	}
+
+	if proposal.SettlementType == "" {
+		proposal.SettlementType = loan.Trade.SettlementType
+	}
+
+	return c.postJSON(ctx, c.cfg.Endpoints.Loans+"/"+loanId+"/returns", proposal, "proposing return of loan ["+loanId+"]")
+}
+
+// AcknowledgeReturn will perform an HTTP POST operation against the 1Source
+// REST API to acknowledge a PENDING return proposed by the counterparty
+func (c *Client) AcknowledgeReturn(ctx context.Context, returnId string, ack models.ReturnAcknowledgement) (string, error) {
+	if ack.AcknowledgementType == "" {
+		ack.AcknowledgementType = models.AcknowledgementTypePositive
+	}
+
+	if ack.Settlement != nil {
+		if err := ack.Settlement.Instruction.Validate(); err != nil {
+			return "", err
+		}
+	}
+
+	ret, loan, err := c.returnAndLoan(ctx, returnId)
+	if err != nil {
+		return "", err
+	}
+
+	if ret.Status != models.ReturnStatusPending {
+		return "", fmt.Errorf("return [%s] is in %s state, not PENDING, and cannot be acknowledged", returnId, ret.Status)
+	}
+
+	if ack.AcknowledgementType == models.AcknowledgementTypePositive {
+		if err := c.checkReturnQuantity(ctx, loan, ret.Quantity, returnId); err != nil {
+			return "", err
+		}
+	}
+
+	if ack.Settlement != nil && ack.Settlement.PartyRole == "" {
+		role, err := c.partyRole(loan)
+		if err != nil {
+			return "", err
+		}
+		ack.Settlement.PartyRole = role
+	}
+
+	return c.postJSON(ctx, c.returnEndPoint(ret)+"/acknowledge", ack, "acknowledging return ["+returnId+"]")
+}
+
+// CancelReturn will perform an HTTP POST operation against the 1Source REST
+// API to cancel a PENDING return
+func (c *Client) CancelReturn(ctx context.Context, returnId string) (string, error) {
+	ret, _, err := c.returnAndLoan(ctx, returnId)
+	if err != nil {
+		return "", err
+	}
+
+	if ret.Status != models.ReturnStatusPending {
+		return "", fmt.Errorf("return [%s] is in %s state, not PENDING, and cannot be canceled", returnId, ret.Status)
+	}
+
+	return c.postJSON(ctx, c.returnEndPoint(ret)+"/cancel", nil, "canceling return ["+returnId+"]")
+}
+
+// SettleReturn will perform an HTTP PATCH operation against the 1Source REST
+// API to mark a pending or acknowledged return as settled
+func (c *Client) SettleReturn(ctx context.Context, returnId string) (string, error) {
+	ret, loan, err := c.returnAndLoan(ctx, returnId)
+	if err != nil {
+		return "", err
+	}
+
+	if ret.Status != models.ReturnStatusPending && ret.Status != models.ReturnStatusAcknowledged {
+		return "", fmt.Errorf("return [%s] is in %s state and cannot be settled", returnId, ret.Status)
+	}
+
+	if ret.SettlementStatus == models.SettlementStatusSettled {
+		return "", fmt.Errorf("return [%s] is already settled", returnId)
+	}
+
+	if err := c.checkReturnQuantity(ctx, loan, ret.Quantity, returnId); err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(models.ReturnSettlementStatusUpdate{SettlementStatus: models.SettlementStatusSettled})
+	if err != nil {
+		return "", err
+	}
+
+	respBody, err := c.Patch(ctx, c.returnEndPoint(ret), body)
+	if err != nil {
+		return "", fmt.Errorf("settling return [%s]: %w", returnId, err)
+	}
+
+	return ledgerMessage(respBody), nil
+}
+
+// returnAndLoan retrieves a return and the loan it applies to, checking that
+// the loan's current status allows returns
+func (c *Client) returnAndLoan(ctx context.Context, returnId string) (*models.Return, *models.Loan, error) {
+	ret, err := c.GetReturn(ctx, returnId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("retrieving return [%s]: %w", returnId, err)
+	}
+
+	loan, err := c.GetLoan(ctx, ret.LoanId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("retrieving loan [%s] of return [%s]: %w", ret.LoanId, returnId, err)
+	}
+
+	if err := loan.CheckAction(models.LoanActionReturn); err != nil {
+		return nil, nil, err
+	}
+
+	return ret, loan, nil
+}
+
+// returnEndPoint returns the loan-scoped endpoint of a return
+func (c *Client) returnEndPoint(ret *models.Return) string {
+	return c.cfg.Endpoints.Loans + "/" + ret.LoanId + "/returns/" + ret.ReturnId
+}
+
+// checkReturnQuantity checks that quantity does not exceed the open quantity
+// of the loan, not counting the return with the given return_id
+func (c *Client) checkReturnQuantity(ctx context.Context, loan *models.Loan, quantity int64, excludeReturnId string) error {
+	open, err := c.OpenQuantity(ctx, loan, excludeReturnId)
+	if err != nil {
+		return err
+	}
+
+	if quantity > open {
+		return fmt.Errorf("return quantity %d exceeds the open quantity %d of loan [%s]", quantity, open, loan.LoanId)
+	}
+
+	return nil
+}
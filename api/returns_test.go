@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+func TestProposeReturnOpenQuantity(t *testing.T) {
+	tests := []struct {
+		name      string
+		tradeDate string
+		quantity  int64
+		wantSince string
+		wantErr   string
+	}{
+		{"within the open quantity", "2023-11-01", 7000, "2023-10-31T00:00:00Z", ""},
+		{"more than the open quantity", "2023-11-01", 7001, "2023-10-31T00:00:00Z", "exceeds the open quantity 7000 of loan [L1]"},
+		{"without a trade date", "", 7000, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := testLoan("L1")
+			loan.Trade.TradeDate = tt.tradeDate
+
+			// Returns of other loans, and settled or canceled ones, do not
+			// count against the open quantity
+			returns := models.Returns{
+				{ReturnId: "T1", LoanId: "L1", Status: models.ReturnStatusPending, Quantity: 2000},
+				{ReturnId: "T2", LoanId: "L1", Status: models.ReturnStatusAcknowledged, Quantity: 1000},
+				{ReturnId: "T3", LoanId: "L1", Status: models.ReturnStatusSettled, Quantity: 500},
+				{ReturnId: "T4", LoanId: "L1", Status: models.ReturnStatusCanceled, Quantity: 500},
+				{ReturnId: "T5", LoanId: "L2", Status: models.ReturnStatusPending, Quantity: 5000},
+			}
+
+			var listed []string
+			posted := false
+
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/v1/ledger/loans/L1/returns":
+					posted = true
+					writeJSON(w, models.LedgerResponse{Message: "return proposed"})
+				case r.URL.Path == "/v1/ledger/loans/L1":
+					writeJSON(w, loan)
+				case r.URL.Path == "/v1/ledger/returns":
+					listed = append(listed, r.URL.Query().Get("since"))
+					writeJSON(w, returns)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			_, err := client.ProposeReturn(context.Background(), "L1", models.ReturnProposal{Quantity: tt.quantity})
+
+			if tt.wantErr == "" && (err != nil || !posted) {
+				t.Errorf("ProposeReturn = %v, posted %v, want the return proposed", err, posted)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr) || posted) {
+				t.Errorf("ProposeReturn = %v, posted %v, want an error containing %q", err, posted, tt.wantErr)
+			}
+
+			// The returns are listed once, from the day before the trade date
+			if len(listed) != 1 || listed[0] != tt.wantSince {
+				t.Errorf("returns listed since %q, want once since %q", listed, tt.wantSince)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// LoanUpdate is the outcome of an update of a loan: the ledger message and
+// the loan as it was before and is after the update
+type LoanUpdate struct {
+	Message string
+	Before  *models.Loan
+	After   *models.Loan
+}
+
+// Changes returns the fields of the loan changed by the update, without
+// the event, party and time of the new version
+func (u *LoanUpdate) Changes() ([]models.FieldChange, error) {
+	return models.LoanChanges(u.Before, u.After)
+}
+
+// UpdateLoanSettlementStatus will perform an HTTP PATCH operation against the
+// 1Source REST API to update the settlement status of a loan, such as to
+// SETTLED once the securities have moved
+// The loan's state must allow settlement and its current settlement status
+// must be able to move to the new one.
+func (c *Client) UpdateLoanSettlementStatus(ctx context.Context, loanId string, status models.SettlementStatus) (*LoanUpdate, error) {
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionSettle)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.SettlementStatus.CanTransition(status) {
+		return nil, fmt.Errorf("settlement status of loan [%s] cannot move from %s to %s", loanId, loan.SettlementStatus, status)
+	}
+
+	update := models.LoanSettlementStatusUpdate{SettlementStatus: status}
+
+	return c.patchLoan(ctx, loan, c.cfg.Endpoints.Loans+"/"+loanId, update, "updating settlement status of loan ["+loanId+"]")
+}
+
+// UpdateSettlementInstruction will perform an HTTP PATCH operation against
+// the 1Source REST API to replace our side's settlement instruction of a loan
+// With amend, only the non-empty fields of the update's instruction are
+// changed and the rest are kept from the loan's current instruction. The
+// party role is filled in from the loan when left empty, and must be ours.
+func (c *Client) UpdateSettlementInstruction(ctx context.Context, loanId string, update models.SettlementInstructionUpdate, amend bool) (*LoanUpdate, error) {
+	loan, err := c.loanFor(ctx, loanId, models.LoanActionSettle)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := c.partyRole(loan)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.PartyRole == "" {
+		update.PartyRole = role
+	} else if update.PartyRole != role {
+		return nil, fmt.Errorf("settlement instruction is for %s but party [%s] is the %s of loan [%s]", update.PartyRole, c.cfg.General.Party_Id, role, loanId)
+	}
+
+	if amend {
+		current, ok := loan.Instruction(role)
+		if !ok {
+			return nil, fmt.Errorf("loan [%s] has no %s settlement instruction to amend", loanId, role)
+		}
+		update.Instruction = current.Instruction.Amend(update.Instruction)
+	}
+
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c.patchLoan(ctx, loan, c.cfg.Endpoints.Loans+"/"+loanId+"/instruction", update, "updating settlement instruction of loan ["+loanId+"]")
+}
+
+// patchLoan encodes body as JSON, PATCHes it to the 1Source REST API and
+// retrieves the loan again to report the update
+func (c *Client) patchLoan(ctx context.Context, before *models.Loan, endPoint string, body any, action string) (*LoanUpdate, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request for %s: %w", action, err)
+	}
+
+	respBody, err := c.Patch(ctx, endPoint, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", action, err)
+	}
+
+	after, err := c.GetLoan(ctx, before.LoanId)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving loan [%s] after %s: %w", before.LoanId, action, err)
+	}
+
+	return &LoanUpdate{Message: ledgerMessage(respBody), Before: before, After: after}, nil
+}
@@ -0,0 +1,188 @@
+// Package api provides functions for HTTP verb access to 1Source REST API.
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// DefaultTokenExpiryDelta is how long before its expiry an Auth Token (or
+// refresh token) is treated as expired, to absorb clock skew and latency
+const DefaultTokenExpiryDelta = 30 * time.Second
+
+// TokenSource manages the lifecycle of the KeyCloak Auth Token. It tracks
+// ExpiresIn and RefreshExpiresIn, refreshes the token with the refresh_token
+// before it expires and falls back to a full login when the refresh fails.
+// A TokenSource is safe for concurrent use by multiple goroutines
+type TokenSource struct {
+	cfg      *models.AppConfig
+	keycloak *gocloak.GoCloak
+
+	mu            sync.Mutex
+	token         *gocloak.JWT
+	expiry        time.Time
+	refreshExpiry time.Time
+}
+
+// NewTokenSource creates a TokenSource which logs into KeyCloak using
+// the grant and credentials from the configuration TOML file
+func NewTokenSource(cfg *models.AppConfig) (*TokenSource, error) {
+	if err := ValidateAuthentication(cfg); err != nil {
+		return nil, err
+	}
+
+	return &TokenSource{
+		cfg:      cfg,
+		keycloak: gocloak.NewClient(cfg.General.Auth_URL),
+	}, nil
+}
+
+// Token returns a valid Auth Token, refreshing it or logging in again
+// when the current one is missing or about to expire
+func (ts *TokenSource) Token(ctx context.Context) (*gocloak.JWT, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+
+	if ts.token != nil && now.Before(ts.expiry) {
+		return ts.token, nil
+	}
+
+	// Try the refresh_token first, if it is still valid
+	if ts.token != nil && ts.token.RefreshToken != "" && now.Before(ts.refreshExpiry) {
+		token, err := ts.refresh(ctx)
+		if err == nil {
+			ts.store(token, now)
+			return token, nil
+		}
+
+		log.Println("Error refreshing Auth token, logging in again: ", err)
+	}
+
+	token, err := ts.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.store(token, now)
+
+	return token, nil
+}
+
+// Bearer returns the Authorization header value for a valid Auth Token
+func (ts *TokenSource) Bearer(ctx context.Context) (string, error) {
+	token, err := ts.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return `Bearer ` + token.AccessToken, nil
+}
+
+// Invalidate marks the Auth Token with the given access token as expired,
+// typically after the 1Source REST API rejected it with a 401. It is a no-op
+// if another goroutine has already replaced that token
+func (ts *TokenSource) Invalidate(accessToken string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != nil && ts.token.AccessToken == accessToken {
+		ts.expiry = time.Time{}
+	}
+}
+
+// login performs a full KeyCloak login using the configured grant_type
+func (ts *TokenSource) login(ctx context.Context) (*gocloak.JWT, error) {
+	var token *gocloak.JWT
+	var err error
+
+	auth := ts.cfg.Authentication
+	grant := grantType(ts.cfg)
+
+	log.Printf("Logging into KeyCloak to get Auth Token (grant_type %s)", grant)
+
+	switch grant {
+	case GrantTypeClientCredentials:
+		token, err = ts.keycloak.LoginClient(
+			ctx,
+			auth.Client_Id,
+			clientSecret(ts.cfg),
+			ts.cfg.General.Realm_Name)
+
+	case GrantTypeRefreshToken:
+		// The configured refresh_token bootstraps the session; afterwards
+		// the refresh_token issued with each Auth Token is used
+		token, err = ts.keycloak.RefreshToken(
+			ctx,
+			auth.Refresh_Token,
+			auth.Client_Id,
+			clientSecret(ts.cfg),
+			ts.cfg.General.Realm_Name)
+
+	default:
+		token, err = ts.keycloak.Login(
+			ctx,
+			auth.Client_Id,
+			clientSecret(ts.cfg),
+			ts.cfg.General.Realm_Name,
+			auth.Username,
+			auth.Password)
+	}
+
+	if err != nil {
+		log.Println("Error retrieving Auth token", err)
+		return nil, fmt.Errorf("retrieving Auth token: %w", authError(ts.cfg, err))
+	}
+
+	log.Println("Successfully received Auth token")
+
+	return token, nil
+}
+
+// refresh exchanges the current refresh_token for a new Auth Token
+func (ts *TokenSource) refresh(ctx context.Context) (*gocloak.JWT, error) {
+	log.Println("Refreshing Auth token")
+
+	token, err := ts.keycloak.RefreshToken(
+		ctx,
+		ts.token.RefreshToken,
+		ts.cfg.Authentication.Client_Id,
+		clientSecret(ts.cfg),
+		ts.cfg.General.Realm_Name)
+
+	if err != nil {
+		return nil, fmt.Errorf("refreshing Auth token: %w", authError(ts.cfg, err))
+	}
+
+	log.Println("Successfully refreshed Auth token")
+
+	return token, nil
+}
+
+// store records a new Auth Token and computes its expiry times
+func (ts *TokenSource) store(token *gocloak.JWT, issued time.Time) {
+	ts.token = token
+	ts.expiry = expiry(issued, token.ExpiresIn)
+	ts.refreshExpiry = expiry(issued, token.RefreshExpiresIn)
+}
+
+// expiry computes when a token issued at the given time and valid for the
+// given number of seconds should be treated as expired
+func expiry(issued time.Time, expiresIn int) time.Time {
+	lifetime := time.Duration(expiresIn) * time.Second
+
+	if lifetime > 2*DefaultTokenExpiryDelta {
+		lifetime -= DefaultTokenExpiryDelta
+	} else {
+		lifetime /= 2
+	}
+
+	return issued.Add(lifetime)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/EquiLend/1Source-Go/bulk"
+)
+
+// proposeLoans proposes loans in bulk from a directory of JSON files, an
+// NDJSON file or a CSV file with a column mapping. It reports whether every
+// row was proposed, skipped or dry run, without failures. A dry run leaves
+// the report of earlier runs as it was
+func proposeLoans(ctx context.Context, source string, options []string) bool {
+	fs := flag.NewFlagSet("-lb", flag.ContinueOnError)
+	mapping := fs.String("mapping", "", "JSON column mapping file [required for CSV]")
+	concurrency := fs.Int("concurrency", bulk.DefaultConcurrency, "number of proposals in flight")
+	report := fs.String("report", "", "CSV report file, defaults to the source with a .report.csv extension")
+	parseOptions(fs, options)
+
+	if *report == "" {
+		*report = filepath.Clean(source) + ".report.csv"
+	}
+
+	rows, err := bulk.Read(source, *mapping)
+	if err != nil {
+		log.Println("Error reading loan proposals: ", err)
+		fmt.Println("Error reading loan proposals: ", err)
+		return false
+	}
+
+	earlier, err := bulk.ReadReport(*report)
+	if err != nil {
+		log.Println("Error reading earlier report: ", err)
+		fmt.Println("Error reading earlier report: ", err)
+		return false
+	}
+
+	// Each row is recorded as soon as it is done, and the report is then
+	// rewritten whole with the loan_id of the new loans
+	var journal *bulk.Journal
+	if !client.IsDryRun() {
+		if journal, err = bulk.OpenJournal(*report); err != nil {
+			log.Println("Error opening report: ", err)
+			fmt.Println("Error opening report: ", err)
+			return false
+		}
+	}
+
+	results := bulk.Propose(ctx, client, rows, earlier, *concurrency, journal)
+
+	if err := journal.Close(); err != nil {
+		log.Println("Error closing report: ", err)
+	}
+
+	written := "Report written to " + *report
+	if client.IsDryRun() {
+		written = "Dry run, report not written"
+	} else if err := bulk.WriteReport(*report, results); err != nil {
+		log.Println("Error writing report: ", err)
+		fmt.Println("Error writing report: ", err)
+	}
+
+	counts := map[bulk.Status]int{}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\tSTATUS\tLOAN ID\tERROR")
+	for _, r := range results {
+		counts[r.Status]++
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Row, r.Status, r.LoanId, r.Error)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d proposed, %d skipped, %d dry run, %d failed. %s\n",
+		counts[bulk.StatusProposed], counts[bulk.StatusSkipped], counts[bulk.StatusDryRun], counts[bulk.StatusFailed], written)
+
+	return counts[bulk.StatusFailed] == 0
+}
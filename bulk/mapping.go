@@ -0,0 +1,293 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Mapping maps the columns of a CSV file onto loan proposals. It is read from
+// a JSON file such as:
+//
+//	{
+//	  "template": "proposed_loan.json",
+//	  "columns": {
+//	    "Ticker": "trade.instrument.ticker",
+//	    "Quantity": "trade.quantity",
+//	    "Borrower": "trade.transactingParties[0].party.partyId"
+//	  }
+//	}
+//
+// Each proposal starts as a copy of the template, if any, and each column
+// sets the field at its JSON path. Empty cells keep the template's value
+type Mapping struct {
+	Template string            `json:"template"`
+	Columns  map[string]string `json:"columns"`
+
+	template *models.LoanProposal
+}
+
+// ReadMapping reads a column mapping file and its template. A relative
+// template path is relative to the mapping file
+func ReadMapping(filename string) (*Mapping, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading mapping file '%s': %w", filename, err)
+	}
+
+	var m Mapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing mapping file '%s': %w", filename, err)
+	}
+
+	if len(m.Columns) == 0 {
+		return nil, fmt.Errorf("mapping file '%s' maps no columns", filename)
+	}
+
+	// Check every path against the model up front, rather than on each row
+	for column, path := range m.Columns {
+		if err := setField(&models.LoanProposal{}, path, ""); err != nil {
+			return nil, fmt.Errorf("mapping of column '%s': %w", column, err)
+		}
+	}
+
+	m.template = &models.LoanProposal{}
+
+	if m.Template != "" {
+		template := m.Template
+		if !filepath.IsAbs(template) {
+			template = filepath.Join(filepath.Dir(filename), template)
+		}
+
+		data, err := os.ReadFile(template)
+		if err != nil {
+			return nil, fmt.Errorf("reading template '%s': %w", template, err)
+		}
+
+		row := decodeRow(template, data)
+		if row.Err != nil {
+			return nil, fmt.Errorf("template '%s': %w", template, row.Err)
+		}
+		m.template = row.Proposal
+	}
+
+	return &m, nil
+}
+
+// readCSV reads one proposal per record of a CSV file with a header row
+func (m *Mapping) readCSV(filename string) ([]Row, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header of '%s': %w", filename, err)
+	}
+
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.TrimSpace(column)] = i
+	}
+
+	for column := range m.Columns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("CSV file '%s' has no column '%s'", filename, column)
+		}
+	}
+
+	var rows []Row
+	for record := 1; ; record++ {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		id := strconv.Itoa(record)
+		if err != nil {
+			rows = append(rows, Row{Id: id, Err: err})
+			continue
+		}
+
+		rows = append(rows, m.row(id, header, index, fields))
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV file '%s' has no records", filename)
+	}
+
+	return rows, nil
+}
+
+// row builds the proposal of one CSV record
+func (m *Mapping) row(id string, header []string, index map[string]int, fields []string) Row {
+	proposal, err := m.copyTemplate()
+	if err != nil {
+		return Row{Id: id, Err: err}
+	}
+
+	for column, path := range m.Columns {
+		value := strings.TrimSpace(fields[index[column]])
+		if value == "" {
+			continue
+		}
+
+		if err := setField(proposal, path, value); err != nil {
+			return Row{Id: id, Err: fmt.Errorf("column '%s': %w", column, err)}
+		}
+	}
+
+	return Row{Id: id, Proposal: proposal}
+}
+
+// copyTemplate returns a deep copy of the template
+func (m *Mapping) copyTemplate() (*models.LoanProposal, error) {
+	data, err := json.Marshal(m.template)
+	if err != nil {
+		return nil, err
+	}
+
+	var proposal models.LoanProposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return nil, err
+	}
+
+	return &proposal, nil
+}
+
+// setField sets the field of a proposal at a JSON path such as
+// trade.transactingParties[0].party.partyId, allocating the pointers and
+// growing the slices on the way. The value is converted to the field's type;
+// an empty value only checks that the path exists
+func setField(proposal *models.LoanProposal, path string, value string) error {
+	v := reflect.ValueOf(proposal).Elem()
+
+	for _, segment := range strings.Split(path, ".") {
+		name, index, err := splitIndex(segment)
+		if err != nil {
+			return fmt.Errorf("path '%s': %w", path, err)
+		}
+
+		v = deref(v)
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("path '%s': '%s' is not an object", path, name)
+		}
+
+		field, ok := fieldByJSONName(v, name)
+		if !ok {
+			return fmt.Errorf("path '%s': no field '%s'", path, name)
+		}
+		v = field
+
+		if index >= 0 {
+			v = deref(v)
+			if v.Kind() != reflect.Slice {
+				return fmt.Errorf("path '%s': '%s' is not an array", path, name)
+			}
+			if v.Len() <= index {
+				grown := reflect.MakeSlice(v.Type(), index+1, index+1)
+				reflect.Copy(grown, v)
+				v.Set(grown)
+			}
+			v = v.Index(index)
+		}
+	}
+
+	v = deref(v)
+
+	switch v.Kind() {
+	case reflect.String, reflect.Int32, reflect.Int64, reflect.Uint64, reflect.Float32, reflect.Float64, reflect.Bool:
+	default:
+		return fmt.Errorf("path '%s' is not a single value", path)
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not an integer", value)
+		}
+		v.SetInt(n)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' is not an integer", value)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not a number", value)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a boolean", value)
+		}
+		v.SetBool(b)
+	}
+
+	return nil
+}
+
+// splitIndex splits a path segment such as settlement[1] into its name and
+// index, which is -1 when there is none
+func splitIndex(segment string) (string, int, error) {
+	name, rest, ok := strings.Cut(segment, "[")
+	if !ok {
+		return segment, -1, nil
+	}
+
+	index, err := strconv.Atoi(strings.TrimSuffix(rest, "]"))
+	if err != nil || !strings.HasSuffix(rest, "]") || index < 0 {
+		return "", 0, fmt.Errorf("invalid index in '%s'", segment)
+	}
+
+	return name, index, nil
+}
+
+// deref follows pointers, allocating those which are nil
+func deref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// fieldByJSONName returns the field of a struct with the given JSON name
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return v.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
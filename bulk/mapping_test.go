@@ -0,0 +1,113 @@
+package bulk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+func TestSetField(t *testing.T) {
+	tests := []struct {
+		path  string
+		value string
+		check func(p *models.LoanProposal) bool
+	}{
+		{"trade.instrument.ticker", "IBM", func(p *models.LoanProposal) bool {
+			return p.Trade.Instrument.Ticker == "IBM"
+		}},
+		{"trade.quantity", "10000", func(p *models.LoanProposal) bool {
+			return p.Trade.Quantity == 10000
+		}},
+		{"trade.collateral.roundingRule", "-10", func(p *models.LoanProposal) bool {
+			return p.Trade.Collateral.RoundingRule != nil && *p.Trade.Collateral.RoundingRule == -10
+		}},
+		{"trade.dividendRatePct", "85.5", func(p *models.LoanProposal) bool {
+			return p.Trade.DividendRatePct == 85.5
+		}},
+		{"trade.collateral.type", "CASH", func(p *models.LoanProposal) bool {
+			return p.Trade.Collateral.Type == models.CollateralTypeCash
+		}},
+		{"trade.instrument.price.value", "101.25", func(p *models.LoanProposal) bool {
+			return p.Trade.Instrument.Price != nil && p.Trade.Instrument.Price.Value == 101.25
+		}},
+		{"trade.rate.fee.baseRate", "0.25", func(p *models.LoanProposal) bool {
+			return p.Trade.Rate.Fee != nil && p.Trade.Rate.Fee.BaseRate == 0.25 && p.Trade.Rate.Rebate == nil
+		}},
+		{"trade.transactingParties[0].party.partyId", "TLEN-US", func(p *models.LoanProposal) bool {
+			return len(p.Trade.TransactingParties) == 1 && p.Trade.TransactingParties[0].Party.PartyId == "TLEN-US"
+		}},
+		{"trade.transactingParties[2].partyRole", "BORROWER", func(p *models.LoanProposal) bool {
+			return len(p.Trade.TransactingParties) == 3 && p.Trade.TransactingParties[2].PartyRole == models.PartyRoleBorrower
+		}},
+		{"trade.executionVenue.venueParties[1].venueId", "V1", func(p *models.LoanProposal) bool {
+			v := p.Trade.ExecutionVenue
+			return v != nil && len(v.VenueParties) == 2 && v.VenueParties[1].VenueId == "V1"
+		}},
+		{"trade.instrument.ticker", "", func(p *models.LoanProposal) bool {
+			return p.Trade.Instrument.Ticker == ""
+		}},
+	}
+
+	for _, tt := range tests {
+		p := &models.LoanProposal{}
+		if err := setField(p, tt.path, tt.value); err != nil {
+			t.Errorf("setField(%s, %q) = %v", tt.path, tt.value, err)
+			continue
+		}
+
+		if !tt.check(p) {
+			t.Errorf("setField(%s, %q) set %+v", tt.path, tt.value, p.Trade)
+		}
+	}
+}
+
+func TestSetFieldKeepsSliceElements(t *testing.T) {
+	p := &models.LoanProposal{}
+	p.Trade.TransactingParties = []models.TransactingParty{{PartyRole: models.PartyRoleLender}}
+
+	if err := setField(p, "trade.transactingParties[1].party.partyId", "TBORR-US"); err != nil {
+		t.Fatalf("setField: %v", err)
+	}
+
+	parties := p.Trade.TransactingParties
+	if len(parties) != 2 || parties[0].PartyRole != models.PartyRoleLender || parties[1].Party.PartyId != "TBORR-US" {
+		t.Errorf("transactingParties = %+v", parties)
+	}
+
+	if err := setField(p, "trade.transactingParties[0].party.partyId", "TLEN-US"); err != nil {
+		t.Fatalf("setField: %v", err)
+	}
+
+	if parties := p.Trade.TransactingParties; len(parties) != 2 || parties[0].Party.PartyId != "TLEN-US" {
+		t.Errorf("transactingParties = %+v", parties)
+	}
+}
+
+func TestSetFieldErrors(t *testing.T) {
+	tests := []struct {
+		path  string
+		value string
+		want  string
+	}{
+		{"trade.ticker", "IBM", "no field 'ticker'"},
+		{"trade.instrument.ticker.code", "IBM", "'code' is not an object"},
+		{"trade.quantity[0]", "1", "'quantity' is not an array"},
+		{"trade.transactingParties[x].partyRole", "LENDER", "invalid index in 'transactingParties[x]'"},
+		{"trade.transactingParties[-1].partyRole", "LENDER", "invalid index"},
+		{"trade.transactingParties[0.partyRole", "LENDER", "invalid index"},
+		{"trade.instrument", "IBM", "is not a single value"},
+		{"trade.transactingParties", "IBM", "is not a single value"},
+		{"trade.quantity", "ten", "'ten' is not an integer"},
+		{"trade.quantity", "1.5", "'1.5' is not an integer"},
+		{"trade.collateral.roundingRule", "3000000000", "is not an integer"},
+		{"trade.dividendRatePct", "high", "'high' is not a number"},
+	}
+
+	for _, tt := range tests {
+		err := setField(&models.LoanProposal{}, tt.path, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("setField(%s, %q) = %v, want an error containing %q", tt.path, tt.value, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// DefaultConcurrency is the number of proposals in flight when none is given
+const DefaultConcurrency = 4
+
+// Propose proposes the loans of the rows, at most concurrency at a time, and
+// returns the result of every row in row order. Each result is recorded in
+// the journal as soon as it is known. Rows which succeeded in the earlier
+// results with the same content are skipped, and rows whose earlier failure
+// is unconfirmed are only proposed again if their loan is not found. Once
+// proposed, the new loans are looked up in a single pass to report their
+// loan_id
+func Propose(ctx context.Context, client *api.Client, rows []Row, earlier map[string]Result, concurrency int, journal *Journal) []Result {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]Result, len(rows))
+	since := time.Now().Add(-time.Minute)
+	found, lookupErr := findEarlierLoans(ctx, client, rows, earlier)
+
+	record := func(r *Result) {
+		if err := journal.Record(*r); err != nil {
+			log.Printf("Error recording row [%s] in the report: %s", r.Row, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, row := range rows {
+		results[i] = Result{Row: row.Id, Hash: hash(row)}
+
+		if row.Err == nil {
+			row.Err = row.Proposal.Validate()
+		}
+
+		prev, ok := earlier[row.Id]
+
+		switch {
+		case row.Err != nil:
+			results[i].Status = StatusFailed
+			results[i].Error = row.Err.Error()
+
+		case ok && prev.Status.succeeded() && prev.Hash == results[i].Hash:
+			results[i].Status = StatusSkipped
+			results[i].LoanId = prev.LoanId
+
+		case found[i] != nil:
+			results[i].Status = StatusSkipped
+			results[i].LoanId = found[i].LoanId
+
+		case ok && prev.Unconfirmed && lookupErr != nil:
+			// Proposing again could duplicate the loan
+			results[i] = prev
+			results[i].Error = "looking up the loan of an unconfirmed proposal: " + lookupErr.Error()
+		}
+
+		if results[i].Status != "" {
+			record(&results[i])
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			results[i].Status = StatusFailed
+			results[i].Error = ctx.Err().Error()
+			record(&results[i])
+			continue
+		}
+
+		wg.Add(1)
+		go func(r *Result, proposal models.LoanProposal) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := client.ProposeLoan(ctx, proposal)
+
+			switch {
+			case errors.Is(err, api.ErrDryRun):
+				r.Status = StatusDryRun
+			case err != nil:
+				log.Printf("Error proposing loan of row [%s]: %s", r.Row, err)
+				r.Status = StatusFailed
+				r.Error = err.Error()
+				r.Unconfirmed = unconfirmed(err)
+			default:
+				r.Status = StatusProposed
+			}
+
+			record(r)
+		}(&results[i], *row.Proposal)
+	}
+
+	wg.Wait()
+
+	findLoanIds(ctx, client, rows, results, since)
+
+	return results
+}
+
+// unconfirmed reports whether a proposal which failed with err may still
+// have reached the ledger: it was canceled, failed in transit or got a 5xx
+// response. Other 1Source errors reject the proposal
+func unconfirmed(err error) bool {
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+
+	return true
+}
+
+// findEarlierLoans looks up the loans of the rows whose earlier proposal
+// failed unconfirmed, by venue reference, so that they are not proposed
+// twice. The loan of any other row is nil
+func findEarlierLoans(ctx context.Context, client *api.Client, rows []Row, earlier map[string]Result) ([]*models.Loan, error) {
+	found := make([]*models.Loan, len(rows))
+
+	var trades []*models.Trade
+	var indexes []int
+
+	for i, row := range rows {
+		if prev, ok := earlier[row.Id]; ok && prev.Unconfirmed && row.Proposal != nil {
+			trades = append(trades, &row.Proposal.Trade)
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(trades) == 0 {
+		return found, nil
+	}
+
+	// The earlier run may be any time ago
+	loans, err := client.FindProposedLoans(ctx, trades, time.Time{})
+	if err != nil {
+		log.Println("Error looking up the loans of unconfirmed proposals: ", err)
+		return found, err
+	}
+
+	for i, loan := range loans {
+		found[indexes[i]] = loan
+	}
+
+	return found, nil
+}
+
+// findLoanIds fills in the loan_id of the rows proposed by this run
+func findLoanIds(ctx context.Context, client *api.Client, rows []Row, results []Result, since time.Time) {
+	var trades []*models.Trade
+	var proposed []*Result
+
+	for i := range results {
+		if results[i].Status == StatusProposed {
+			trades = append(trades, &rows[i].Proposal.Trade)
+			proposed = append(proposed, &results[i])
+		}
+	}
+
+	if len(trades) == 0 {
+		return
+	}
+
+	loans, err := client.FindProposedLoans(ctx, trades, since)
+	if err != nil {
+		log.Println("Error looking up proposed loans: ", err)
+	}
+
+	for i, loan := range loans {
+		if loan != nil {
+			proposed[i].LoanId = loan.LoanId
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package bulk
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Status is the outcome of proposing one row
+type Status string
+
+// Status values
+const (
+	// StatusProposed means the loan was proposed by this run
+	StatusProposed Status = "PROPOSED"
+	// StatusSkipped means the loan was proposed by an earlier run
+	StatusSkipped Status = "SKIPPED"
+	// StatusFailed means the row could not be read, validated or proposed
+	StatusFailed Status = "FAILED"
+	// StatusDryRun means the request was printed instead of sent
+	StatusDryRun Status = "DRY RUN"
+)
+
+// succeeded reports whether the row's loan was proposed, by this run or an
+// earlier one
+func (s Status) succeeded() bool {
+	return s == StatusProposed || s == StatusSkipped
+}
+
+// reportHeader is the header row of a report file
+var reportHeader = []string{"row", "status", "loan_id", "error", "hash", "unconfirmed"}
+
+// Result is the outcome of proposing one row. Hash identifies the content
+// of the proposal, so that a rerun only skips a row which is unchanged.
+// Unconfirmed is set on a failure which may have reached the ledger, such as
+// a canceled or broken connection, so that a rerun looks for the loan before
+// proposing it again
+type Result struct {
+	Row         string
+	Status      Status
+	LoanId      string
+	Error       string
+	Hash        string
+	Unconfirmed bool
+}
+
+// record returns the report record of a result
+func (r Result) record() []string {
+	unconfirmed := ""
+	if r.Unconfirmed {
+		unconfirmed = "true"
+	}
+
+	// One line per record, so that a torn last line spoils no other record
+	errorText := strings.Join(strings.Fields(r.Error), " ")
+
+	return []string{r.Row, string(r.Status), r.LoanId, errorText, r.Hash, unconfirmed}
+}
+
+// hash returns the hash of a loan proposal's content
+func hash(row Row) string {
+	if row.Proposal == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(row.Proposal)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadReport reads the results of earlier runs by row, the last record of a
+// row winning. A missing report file is not an error and returns no
+// results. Records are read one line at a time and a torn line, left by a
+// run which was killed while writing it, is skipped. Reports without the
+// unconfirmed column are read as confirmed
+func ReadReport(filename string) (map[string]Result, error) {
+	results := map[string]Result{}
+
+	file, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		record, err := csv.NewReader(strings.NewReader(scanner.Text())).Read()
+		if err != nil {
+			log.Printf("Skipping line %d of report '%s': %s", line, filename, err)
+			continue
+		}
+
+		if line == 1 || len(record) < len(reportHeader)-1 || len(record) > len(reportHeader) {
+			continue
+		}
+
+		results[record[0]] = Result{
+			Row:         record[0],
+			Status:      Status(record[1]),
+			LoanId:      record[2],
+			Error:       record[3],
+			Hash:        record[4],
+			Unconfirmed: len(record) > 5 && record[5] == "true",
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading report '%s': %w", filename, err)
+	}
+
+	return results, nil
+}
+
+// WriteReport writes the results of a run, one record per row, replacing
+// any earlier report. It writes to a temporary file which is synced and then
+// renamed over the report, so that a crash leaves either report whole
+func WriteReport(filename string, results []Result) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing report '%s': %w", filename, err)
+	}
+
+	w := csv.NewWriter(tmp)
+	w.Write(reportHeader)
+	for _, r := range results {
+		w.Write(r.record())
+	}
+	w.Flush()
+
+	err = w.Error()
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing report '%s': %w", filename, err)
+	}
+
+	return nil
+}
+
+// Journal appends the result of each row to a report file as soon as it is
+// known, so that a run which is killed part way still records the rows it
+// has proposed. A nil Journal records nothing, as in a dry run
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *csv.Writer
+}
+
+// OpenJournal opens a report file for appending, writing the header row
+// if it is new
+func OpenJournal(filename string) (*Journal, error) {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{file: file, w: csv.NewWriter(file)}
+
+	// A new report gets its header, and a torn last line is ended so that
+	// it does not run into the next record
+	info, err := file.Stat()
+	if err == nil && info.Size() == 0 {
+		j.w.Write(reportHeader)
+		err = j.flush()
+	} else if err == nil {
+		last := make([]byte, 1)
+		if _, err = file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			_, err = file.WriteString("\n")
+		}
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("opening report '%s': %w", filename, err)
+	}
+
+	return j, nil
+}
+
+// Record appends a result to the report and syncs it to disk
+func (j *Journal) Record(r Result) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.w.Write(r.record())
+
+	return j.flush()
+}
+
+// flush writes the buffered records through to disk
+func (j *Journal) flush() error {
+	j.w.Flush()
+	if err := j.w.Error(); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+// Close closes the report file
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+
+	return j.file.Close()
+}
@@ -0,0 +1,82 @@
+package bulk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.csv")
+
+	journal, err := OpenJournal(filename)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+
+	results := []Result{
+		{Row: "1", Status: StatusProposed, LoanId: "L1", Hash: "h1"},
+		{Row: "2", Status: StatusFailed, Error: "Post \"https://x\":\ncontext canceled", Hash: "h2", Unconfirmed: true},
+	}
+	for _, r := range results {
+		if err := journal.Record(r); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	// A run killed while writing a record leaves a torn last line
+	journal.file.WriteString(`3,PROPOSED,L3,"unterminated`)
+	journal.Close()
+
+	journal, err = OpenJournal(filename)
+	if err != nil {
+		t.Fatalf("OpenJournal again: %v", err)
+	}
+	journal.Record(Result{Row: "1", Status: StatusSkipped, LoanId: "L1", Hash: "h1"})
+	journal.Close()
+
+	got, err := ReadReport(filename)
+	if err != nil {
+		t.Fatalf("ReadReport: %v", err)
+	}
+
+	want := map[string]Result{
+		"1": {Row: "1", Status: StatusSkipped, LoanId: "L1", Hash: "h1"},
+		"2": {Row: "2", Status: StatusFailed, Error: "Post \"https://x\": context canceled", Hash: "h2", Unconfirmed: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ReadReport = %+v, want %+v", got, want)
+	}
+	for row, r := range want {
+		if got[row] != r {
+			t.Errorf("row %s = %+v, want %+v", row, got[row], r)
+		}
+	}
+}
+
+func TestReadReport(t *testing.T) {
+	dir := t.TempDir()
+
+	if got, err := ReadReport(filepath.Join(dir, "missing.csv")); err != nil || len(got) != 0 {
+		t.Errorf("ReadReport of a missing file = %+v, %v", got, err)
+	}
+
+	// Reports written before the unconfirmed column are read as confirmed
+	filename := filepath.Join(dir, "old.csv")
+	os.WriteFile(filename, []byte("row,status,loan_id,error,hash\n1,PROPOSED,L1,,h1\n2,FAILED,,bad,h2\n"), 0644)
+
+	got, err := ReadReport(filename)
+	if err != nil {
+		t.Fatalf("ReadReport: %v", err)
+	}
+	if got["1"].LoanId != "L1" || got["2"].Status != StatusFailed || got["2"].Unconfirmed {
+		t.Errorf("ReadReport = %+v", got)
+	}
+
+	if err := WriteReport(filename, []Result{{Row: "1", Status: StatusDryRun}}); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	if got, _ := ReadReport(filename); len(got) != 1 || got["1"].Status != StatusDryRun {
+		t.Errorf("ReadReport after WriteReport = %+v", got)
+	}
+}
@@ -0,0 +1,136 @@
+// Package bulk proposes loans in bulk from a directory of JSON files, an
+// NDJSON file or a CSV file with a column mapping, and reports the outcome
+// of every row so that a rerun can skip the rows which already succeeded
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Row is one loan proposal of a bulk source. Id identifies the row in the
+// source: the file name for a directory, the line number for an NDJSON file
+// and the record number for a CSV file. Err is set when the row could not be
+// read into a proposal
+type Row struct {
+	Id       string
+	Proposal *models.LoanProposal
+	Err      error
+}
+
+// Read reads the loan proposals of a bulk source. A directory is read as one
+// JSON file per proposal, a .csv file requires a column mapping file and
+// any other file is read as NDJSON, one proposal per line
+func Read(source string, mappingFile string) ([]Row, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case info.IsDir():
+		return readDir(source)
+	case strings.EqualFold(filepath.Ext(source), ".csv"):
+		if mappingFile == "" {
+			return nil, fmt.Errorf("CSV source '%s' requires a column mapping file", source)
+		}
+
+		mapping, err := ReadMapping(mappingFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return mapping.readCSV(source)
+	default:
+		return readNDJSON(source)
+	}
+}
+
+// readDir reads every .json file of a directory, in name order
+func readDir(dir string) ([]Row, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, decodeRow(entry.Name(), data))
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("directory '%s' has no JSON files", dir)
+	}
+
+	return rows, nil
+}
+
+// readNDJSON reads one proposal per non-empty line of a file
+func readNDJSON(filename string) ([]Row, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rows []Row
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		rows = append(rows, decodeRow(strconv.Itoa(line), slices.Clone(data)))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading '%s': %w", filename, err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file '%s' has no loan proposals", filename)
+	}
+
+	return rows, nil
+}
+
+// decodeRow decodes a loan proposal, rejecting fields which are not part of
+// the 1Source loan proposal model
+func decodeRow(id string, data []byte) Row {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var proposal models.LoanProposal
+	if err := dec.Decode(&proposal); err != nil {
+		return Row{Id: id, Err: fmt.Errorf("parsing loan proposal: %w", err)}
+	}
+
+	if dec.More() {
+		return Row{Id: id, Err: errors.New("parsing loan proposal: more than one JSON value")}
+	}
+
+	return Row{Id: id, Proposal: &proposal}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// submitBuyin submits a buy-in of securities on a loan_id
+func submitBuyin(ctx context.Context, loanId string, options []string) {
+	fs := flag.NewFlagSet("-bp", flag.ContinueOnError)
+	quantity := fs.Int64("quantity", 0, "executed quantity of the buy-in [required]")
+	price := fs.Float64("price", 0, "executed price per share of the buy-in [required]")
+	currency := fs.String("currency", "", "currency of the price, defaults to the loan's billing currency")
+	parseOptions(fs, options)
+
+	proposal := models.BuyinProposal{
+		Quantity: *quantity,
+		Price: models.Price{
+			Value:    *price,
+			Currency: *currency,
+			Unit:     models.PriceUnitShare,
+		},
+	}
+
+	if proposal.Price.Currency == "" {
+		loan, err := client.GetLoan(ctx, loanId)
+		if err != nil {
+			fmt.Println("Error retrieving loan: ", err)
+			return
+		}
+		proposal.Price.Currency = loan.Trade.BillingCurrency
+	}
+
+	resp, err := client.SubmitBuyin(ctx, loanId, proposal)
+	printOutcome(resp, err, "Error submitting buy-in: ")
+}
+
+// acceptBuyin accepts a buy-in submitted by the lender
+func acceptBuyin(ctx context.Context, buyinId string) {
+	resp, err := client.AcceptBuyin(ctx, buyinId)
+	printOutcome(resp, err, "Error accepting buy-in: ")
+}
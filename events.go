@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/events"
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/EquiLend/1Source-Go/utils"
+)
+
+// followerFlags holds the command line options of the commands which
+// follow new events from a checkpoint file
+type followerFlags struct {
+	Interval time.Duration
+	Lookback time.Duration
+	Since    string
+}
+
+// Register adds the follower options to a flag set
+func (ff *followerFlags) Register(fs *flag.FlagSet) {
+	fs.DurationVar(&ff.Interval, "interval", events.DefaultInterval, "time between polls of the events endpoint")
+	fs.DurationVar(&ff.Lookback, "lookback", events.DefaultLookback, "how far before the last event each poll looks for late events")
+	fs.StringVar(&ff.Since, "since", "", "follow from this time (RFC3339 or YYYY-MM-DD) when there is no checkpoint yet, defaults to now")
+}
+
+// Follower creates a Follower of the events after the checkpoint file and
+// reports where it starts from
+func (ff *followerFlags) Follower(checkpointFile string) (*events.Follower, error) {
+	start := time.Now()
+	if ff.Since != "" {
+		var err error
+		if start, err = parseTime(ff.Since); err != nil {
+			fmt.Println("Error parsing --since: ", err)
+			os.Exit(30)
+		}
+	}
+
+	follower := &events.Follower{
+		Client:         client,
+		CheckpointFile: checkpointFile,
+		Interval:       ff.Interval,
+		Lookback:       ff.Lookback,
+		Start:          start,
+	}
+
+	cp, err := follower.Checkpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.IsZero() {
+		fmt.Printf("Following 1Source events since %s\n", start.Format(time.RFC3339))
+	} else {
+		fmt.Printf("Following 1Source events after event [%d]\n", cp.LastEventId)
+	}
+
+	return follower, nil
+}
+
+// followEvents prints new events as they arrive, resuming after the event
+// recorded in the checkpoint file
+func followEvents(ctx context.Context, checkpointFile string, options []string) {
+	fs := flag.NewFlagSet("-ef", flag.ContinueOnError)
+	var ff followerFlags
+	ff.Register(fs)
+	parseOptions(fs, options)
+
+	follower, err := ff.Follower(checkpointFile)
+	if err != nil {
+		log.Println("Error loading checkpoint: ", err)
+		fmt.Println("Error loading checkpoint: ", err)
+		return
+	}
+
+	err = follower.Follow(ctx, func(e models.Event) error {
+		printEvent(e)
+		return nil
+	})
+
+	reportFollowError(err)
+	printSequence(follower.Sequence())
+}
+
+// relayEvents POSTs new events to the webhooks of the configuration TOML
+// file as they arrive, resuming after the event recorded in the checkpoint
+// file
+func relayEvents(ctx context.Context, checkpointFile string, options []string) {
+	fs := flag.NewFlagSet("-wr", flag.ContinueOnError)
+	var ff followerFlags
+	ff.Register(fs)
+	parseOptions(fs, options)
+
+	relay, err := events.NewRelay(client, appConfig)
+	if err != nil {
+		log.Println("Error creating webhook relay: ", err)
+		fmt.Println("Error creating webhook relay: ", err)
+		return
+	}
+
+	follower, err := ff.Follower(checkpointFile)
+	if err != nil {
+		log.Println("Error loading checkpoint: ", err)
+		fmt.Println("Error loading checkpoint: ", err)
+		return
+	}
+
+	err = follower.Follow(ctx, func(e models.Event) error {
+		printEvent(e)
+		return relay.Deliver(ctx, e)
+	})
+
+	reportFollowError(err)
+	printSequence(follower.Sequence())
+}
+
+// listEvents prints the events of the list options as one JSON array, in
+// EventId order, followed by the report of their sequence
+func listEvents(ctx context.Context, opts api.ListOptions) {
+	header := "1Source Events"
+	list, sequence, err := events.List(ctx, client, opts, 0)
+	if err != nil {
+		utils.PrintResults(err, "", "Error retrieving 1Source Events: ", header)
+		return
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	utils.PrintResults(err, string(data), "Error retrieving 1Source Events: ", header)
+	printSequence(sequence)
+}
+
+// printSequence prints the report of an event sequence, logging the event
+// IDs still missing
+func printSequence(sequence *events.SequenceReport) {
+	fmt.Println("Event sequence: ", sequence)
+
+	if len(sequence.Missing) > 0 {
+		log.Println("Missing 1Source events: ", sequence)
+	}
+}
+
+// printEvent prints an event on one line
+func printEvent(e models.Event) {
+	fmt.Printf("%d\t%s\t%s\t%s\n", e.EventId, e.EventDateTime, e.EventType, e.ResourceUri)
+}
+
+// reportFollowError reports why following events stopped, unless it was
+// stopped by the user
+func reportFollowError(err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Println("Error following events: ", err)
+		fmt.Println("Error following events: ", err)
+	}
+}
+
+// parseTime parses a time given as RFC3339 or as a YYYY-MM-DD date
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s': expected RFC3339 or YYYY-MM-DD", value)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,97 @@
+// Package events consumes the event stream of the 1Source REST API: it
+// follows new events from a durable checkpoint so that a restarted consumer
+// resumes exactly where it stopped, and dispatches them to the handlers
+// registered for their event type
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Checkpoint is the high-water mark of the events consumed: the last event
+// handled, by EventId, and when it happened
+type Checkpoint struct {
+	LastEventId       uint64 `json:"lastEventId"`
+	LastEventDateTime string `json:"lastEventDateTime,omitempty"`
+}
+
+// LoadCheckpoint reads a checkpoint file. A missing file is not an error and
+// returns an empty checkpoint
+func LoadCheckpoint(filename string) (*Checkpoint, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Checkpoint{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint '%s': %w", filename, err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint '%s': %w", filename, err)
+	}
+
+	return &cp, nil
+}
+
+// IsZero reports whether no event has been consumed yet
+func (cp *Checkpoint) IsZero() bool {
+	return cp.LastEventId == 0
+}
+
+// Advance moves the checkpoint past an event
+func (cp *Checkpoint) Advance(event models.Event) {
+	cp.LastEventId = event.EventId
+	cp.LastEventDateTime = event.EventDateTime
+}
+
+// Time returns when the last event consumed happened, or the zero time
+func (cp *Checkpoint) Time() time.Time {
+	t, err := time.Parse(time.RFC3339, cp.LastEventDateTime)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// Save writes the checkpoint durably: to a temporary file which is synced
+// and then renamed over the checkpoint file, so that a crash leaves either
+// the old or the new checkpoint and never a partial one
+func (cp *Checkpoint) Save(filename string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("saving checkpoint '%s': %w", filename, err)
+	}
+
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("saving checkpoint '%s': %w", filename, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// DeadLetter appends the events which could not be handled to a file, one
+// JSON record per line, so that they can be inspected and replayed
+type DeadLetter struct {
+	File string
+
+	mu sync.Mutex
+}
+
+// DeadLetterRecord is one line of a dead-letter file
+type DeadLetterRecord struct {
+	Event    models.Event `json:"event"`
+	Attempts int          `json:"attempts"`
+	Error    string       `json:"error"`
+	FailedAt string       `json:"failedAt"`
+}
+
+// Write appends an event and the error it last failed with
+func (d *DeadLetter) Write(event models.Event, attempts int, cause error) error {
+	record := DeadLetterRecord{
+		Event:    event,
+		Attempts: attempts,
+		Error:    cause.Error(),
+		FailedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := os.OpenFile(d.File, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("writing event [%d] to dead-letter file '%s': %w", event.EventId, d.File, err)
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing event [%d] to dead-letter file '%s': %w", event.EventId, d.File, err)
+	}
+
+	return nil
+}
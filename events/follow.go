@@ -0,0 +1,154 @@
+package events
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Defaults of a Follower
+const (
+	DefaultInterval = 10 * time.Second
+	DefaultLookback = time.Minute
+)
+
+// Follower polls the events endpoint of the 1Source REST API for the events
+// after its checkpoint and hands them over in EventId order. Each poll looks
+// Lookback before the checkpoint's time, to allow for events committed late,
+// as described for After
+type Follower struct {
+	Client         *api.Client
+	CheckpointFile string
+	Interval       time.Duration
+	Lookback       time.Duration
+
+	// Start is when to follow from when there is no checkpoint yet. The
+	// zero time follows from the first event
+	Start time.Time
+
+	checkpoint *Checkpoint
+	sequence   SequenceReport
+}
+
+// Checkpoint returns the current checkpoint, loading it on first use
+func (f *Follower) Checkpoint() (*Checkpoint, error) {
+	if f.checkpoint == nil {
+		cp, err := LoadCheckpoint(f.CheckpointFile)
+		if err != nil {
+			return nil, err
+		}
+		f.checkpoint = cp
+	}
+
+	return f.checkpoint, nil
+}
+
+// Poll returns the events after the checkpoint, in EventId order, without
+// advancing it. The sequence of the events is checked as described for List
+// and added to the Sequence of the Follower
+func (f *Follower) Poll(ctx context.Context) (models.Events, error) {
+	cp, err := f.Checkpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	events, report, err := After(ctx, f.Client, cp, f.Start, f.Lookback)
+	if err != nil {
+		return nil, err
+	}
+
+	if !report.Clean() {
+		log.Println("1Source event sequence: ", report)
+	}
+	f.sequence.Add(report)
+
+	return events, nil
+}
+
+// Sequence returns the report of the sequence of every event polled so far.
+// Its Missing gaps are the EventIds never received
+func (f *Follower) Sequence() *SequenceReport {
+	return &f.sequence
+}
+
+// After returns the events after a checkpoint, in EventId order, with the
+// report of their sequence as described for List. As the events endpoint can
+// only be filtered by time, it asks for the events since the checkpoint's
+// time less lookback and drops those at or before the checkpoint's EventId.
+// With an empty checkpoint, it returns the events since start, or every
+// event for the zero time
+func After(ctx context.Context, client *api.Client, cp *Checkpoint, start time.Time, lookback time.Duration) (models.Events, *SequenceReport, error) {
+	since := start
+	if !cp.IsZero() {
+		if lookback <= 0 {
+			lookback = DefaultLookback
+		}
+
+		// Without the time of the last event, every event is fetched
+		since = time.Time{}
+		if last := cp.Time(); !last.IsZero() {
+			since = last.Add(-lookback)
+		}
+	}
+
+	return List(ctx, client, api.ListOptions{Since: since}, cp.LastEventId)
+}
+
+// SortEvents sorts events in EventId order
+func SortEvents(events models.Events) {
+	slices.SortFunc(events, func(a, b models.Event) int {
+		switch {
+		case a.EventId < b.EventId:
+			return -1
+		case a.EventId > b.EventId:
+			return 1
+		}
+		return 0
+	})
+}
+
+// Follow polls for new events every Interval until ctx is done, calling
+// handle for each one in EventId order. The checkpoint is saved after each
+// event is handled, so a restart resumes with the next one. An error from
+// handle stops Follow without advancing the checkpoint; errors polling the
+// events endpoint are logged and polling carries on
+func (f *Follower) Follow(ctx context.Context, handle func(models.Event) error) error {
+	interval := f.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	if _, err := f.Checkpoint(); err != nil {
+		return err
+	}
+
+	for {
+		events, err := f.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println("Error polling 1Source events: ", err)
+		}
+
+		for _, event := range events {
+			if err := handle(event); err != nil {
+				return err
+			}
+
+			f.checkpoint.Advance(event)
+			if err := f.checkpoint.Save(f.CheckpointFile); err != nil {
+				return err
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// ErrUnknownEventType is returned for an event type whose entity model is
+// not known, such as one added to the ledger after this module
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// handler is a registered handler, taking the entity as returned by newEntity
+type handler func(ctx context.Context, event models.Event, entity any) error
+
+// Registry dispatches events to the handlers registered for their type.
+// Each handler gets the event and the entity its resourceUri refers to,
+// decoded into its model type. A failing handler is retried with backoff
+// according to Retry, and an event whose handlers keep failing is written to
+// the dead-letter file. It can be used with a Follower:
+//
+//	registry := events.NewRegistry(client, api.NewRetryPolicy(cfg), "events.deadletter.ndjson")
+//	events.On(registry, models.EventTypeContractProposed, func(ctx context.Context, e models.Event, loan *models.Loan) error {
+//		...
+//	})
+//	follower.Follow(ctx, registry.Handler(ctx))
+type Registry struct {
+	client     *api.Client
+	retry      api.RetryPolicy
+	deadLetter *DeadLetter
+	handlers   map[models.EventType][]handler
+}
+
+// NewRegistry creates a Registry which fetches entities with the client and
+// retries failing handlers according to retry. Events whose handlers fail
+// MaxAttempts times are appended to the deadLetterFile
+func NewRegistry(client *api.Client, retry api.RetryPolicy, deadLetterFile string) *Registry {
+	return &Registry{
+		client:     client,
+		retry:      retry,
+		deadLetter: &DeadLetter{File: deadLetterFile},
+		handlers:   map[models.EventType][]handler{},
+	}
+}
+
+// On registers a handler for the events of a type. T is the model of the
+// entity the events refer to: models.Agreement for TRADE events, models.Loan
+// for CONTRACT_ events, and models.Rerate, models.Return, models.Recall or
+// models.Buyin for the RERATE_, RETURN_, RECALL_ and BUYIN_ events. An
+// error is returned if T is not the model of the event type
+func On[T any](r *Registry, eventType models.EventType, handle func(ctx context.Context, event models.Event, entity *T) error) error {
+	entity, ok := newEntity(eventType)
+	if !ok {
+		return fmt.Errorf("%w %s", ErrUnknownEventType, eventType)
+	}
+
+	if _, ok := entity.(*T); !ok {
+		return fmt.Errorf("%s events refer to a %T, not a *%T", eventType, entity, *new(T))
+	}
+
+	r.handlers[eventType] = append(r.handlers[eventType], func(ctx context.Context, event models.Event, entity any) error {
+		return handle(ctx, event, entity.(*T))
+	})
+
+	return nil
+}
+
+// Handles reports whether any handler is registered for the event type
+func (r *Registry) Handles(eventType models.EventType) bool {
+	return len(r.handlers[eventType]) > 0
+}
+
+// Handler returns a function handing events to Dispatch, for a Follower
+func (r *Registry) Handler(ctx context.Context) func(models.Event) error {
+	return func(event models.Event) error {
+		return r.Dispatch(ctx, event)
+	}
+}
+
+// Dispatch fetches the entity of an event and calls the handlers registered
+// for its type, retrying what failed with backoff. An event which still fails
+// after the last attempt is written to the dead-letter file and is not an
+// error, so that one bad event does not hold up the ones after it. Events
+// without handlers are ignored
+func (r *Registry) Dispatch(ctx context.Context, event models.Event) error {
+	handlers := r.handlers[event.EventType]
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	attempts := max(r.retry.MaxAttempts, 1)
+	done := make([]bool, len(handlers))
+
+	var entity any
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if entity == nil {
+			entity, err = Resolve(ctx, r.client, event)
+		}
+
+		if entity != nil {
+			err = nil
+			for i, handle := range handlers {
+				if done[i] {
+					continue
+				}
+
+				if herr := handle(ctx, event, entity); herr != nil {
+					err = errors.Join(err, herr)
+				} else {
+					done[i] = true
+				}
+			}
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if attempt >= attempts {
+			log.Printf("Event [%d] %s failed after %d attempts: %s", event.EventId, event.EventType, attempt, err)
+			return r.deadLetter.Write(event, attempt, err)
+		}
+
+		log.Printf("Retrying event [%d] %s (attempt %d of %d): %s", event.EventId, event.EventType, attempt+1, attempts, err)
+
+		if err := r.retry.Wait(ctx, attempt, err); err != nil {
+			return err
+		}
+	}
+}
+
+// Resolve fetches the entity an event's resourceUri refers to, decoded into
+// the model of the event type. An event type without a known model returns
+// ErrUnknownEventType
+func Resolve(ctx context.Context, client *api.Client, event models.Event) (any, error) {
+	entity, ok := newEntity(event.EventType)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownEventType, event.EventType)
+	}
+
+	if err := client.GetResource(ctx, event.ResourceUri, entity); err != nil {
+		return nil, fmt.Errorf("resolving %s of event [%d]: %w", event.ResourceUri, event.EventId, err)
+	}
+
+	return entity, nil
+}
+
+// newEntity returns a new model of the entity events of a type refer to
+func newEntity(eventType models.EventType) (any, bool) {
+	t := string(eventType)
+
+	switch {
+	case eventType == models.EventTypeTrade:
+		return new(models.Agreement), true
+	case strings.HasPrefix(t, "CONTRACT_"):
+		return new(models.Loan), true
+	case strings.HasPrefix(t, "RERATE_"):
+		return new(models.Rerate), true
+	case strings.HasPrefix(t, "RETURN_"):
+		return new(models.Return), true
+	case strings.HasPrefix(t, "RECALL_"):
+		return new(models.Recall), true
+	case strings.HasPrefix(t, "BUYIN_"):
+		return new(models.Buyin), true
+	}
+
+	return nil, false
+}
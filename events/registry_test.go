@@ -0,0 +1,311 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/internal/ledgertest"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// testRetry retries three times without waiting long
+var testRetry = api.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+// newTestRegistry creates a Registry of a fake ledger, with its dead-letter
+// file in a temporary directory
+func newTestRegistry(t *testing.T, ledger *ledgertest.Ledger) (*Registry, string) {
+	t.Helper()
+
+	deadLetterFile := filepath.Join(t.TempDir(), "deadletter.ndjson")
+
+	return NewRegistry(ledger.Client(t), testRetry, deadLetterFile), deadLetterFile
+}
+
+// readDeadLetters reads the records of a dead-letter file, none if it does
+// not exist
+func readDeadLetters(t *testing.T, filename string) []DeadLetterRecord {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("reading dead-letter file: %v", err)
+	}
+
+	var records []DeadLetterRecord
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec DeadLetterRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decoding dead-letter record %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+
+	return records
+}
+
+// loanEvent returns an event of a type on loan L1 of the fake ledger
+func loanEvent(id uint64, eventType models.EventType) models.Event {
+	return models.Event{EventId: id, EventType: eventType, ResourceUri: ledgertest.Base + "loans/L1"}
+}
+
+func TestOn(t *testing.T) {
+	registry := NewRegistry(nil, testRetry, "")
+
+	tests := []struct {
+		name    string
+		on      func() error
+		wantErr string
+	}{
+		{"agreement of TRADE", func() error {
+			return On(registry, models.EventTypeTrade, func(context.Context, models.Event, *models.Agreement) error { return nil })
+		}, ""},
+		{"loan of CONTRACT_", func() error {
+			return On(registry, models.EventTypeContractOpened, func(context.Context, models.Event, *models.Loan) error { return nil })
+		}, ""},
+		{"rerate of RERATE_", func() error {
+			return On(registry, models.EventTypeRerateProposed, func(context.Context, models.Event, *models.Rerate) error { return nil })
+		}, ""},
+		{"buy-in of BUYIN_", func() error {
+			return On(registry, models.EventTypeBuyinProposed, func(context.Context, models.Event, *models.Buyin) error { return nil })
+		}, ""},
+		{"loan of RERATE_", func() error {
+			return On(registry, models.EventTypeRerateApproved, func(context.Context, models.Event, *models.Loan) error { return nil })
+		}, "RERATE_APPROVED events refer to a *models.Rerate, not a *models.Loan"},
+		{"agreement of CONTRACT_", func() error {
+			return On(registry, models.EventTypeContractProposed, func(context.Context, models.Event, *models.Agreement) error { return nil })
+		}, "refer to a *models.Loan, not a *models.Agreement"},
+		{"unknown event type", func() error {
+			return On(registry, "COLLATERAL_MARKED", func(context.Context, models.Event, *models.Loan) error { return nil })
+		}, "unknown event type COLLATERAL_MARKED"},
+	}
+
+	for _, tt := range tests {
+		err := tt.on()
+
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: On = %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: On = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	for _, eventType := range []models.EventType{models.EventTypeTrade, models.EventTypeContractOpened, models.EventTypeRerateProposed, models.EventTypeBuyinProposed} {
+		if !registry.Handles(eventType) {
+			t.Errorf("no handler registered for %s", eventType)
+		}
+	}
+
+	for _, eventType := range []models.EventType{models.EventTypeRerateApproved, models.EventTypeContractProposed, "COLLATERAL_MARKED"} {
+		if registry.Handles(eventType) {
+			t.Errorf("a rejected handler was registered for %s", eventType)
+		}
+	}
+
+	if err := On(registry, "COLLATERAL_MARKED", func(context.Context, models.Event, *models.Loan) error { return nil }); !errors.Is(err, ErrUnknownEventType) {
+		t.Errorf("On = %v, want ErrUnknownEventType", err)
+	}
+}
+
+func TestDispatchByType(t *testing.T) {
+	ledger := ledgertest.New(t)
+	ledger.Set(ledgertest.Base+"loans/L1", models.Loan{LoanId: "L1", LoanStatus: models.LoanStatusOpen})
+	registry, deadLetterFile := newTestRegistry(t, ledger)
+
+	var got []string
+	On(registry, models.EventTypeContractOpened, func(ctx context.Context, e models.Event, loan *models.Loan) error {
+		got = append(got, "opened "+loan.LoanId+" "+string(loan.LoanStatus))
+		return nil
+	})
+	On(registry, models.EventTypeContractClosed, func(ctx context.Context, e models.Event, loan *models.Loan) error {
+		got = append(got, "closed "+loan.LoanId)
+		return nil
+	})
+
+	ctx := context.Background()
+	for _, e := range []models.Event{loanEvent(1, models.EventTypeContractOpened), loanEvent(2, models.EventTypeContractPending)} {
+		if err := registry.Dispatch(ctx, e); err != nil {
+			t.Fatalf("Dispatch of event [%d]: %v", e.EventId, err)
+		}
+	}
+
+	if len(got) != 1 || got[0] != "opened L1 OPEN" {
+		t.Errorf("handlers called %v, want only the CONTRACT_OPENED one with loan L1", got)
+	}
+
+	// The entity of an event without handlers is not fetched
+	if requests := ledger.Requests(); len(requests) != 1 {
+		t.Errorf("ledger requests = %v, want one GET of loan L1", requests)
+	}
+
+	if records := readDeadLetters(t, deadLetterFile); len(records) != 0 {
+		t.Errorf("dead letters = %+v, want none", records)
+	}
+}
+
+func TestDispatchRetriesFailedHandlers(t *testing.T) {
+	ledger := ledgertest.New(t)
+	ledger.Set(ledgertest.Base+"loans/L1", models.Loan{LoanId: "L1"})
+	registry, deadLetterFile := newTestRegistry(t, ledger)
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	handler := func(name string, failures int) func(context.Context, models.Event, *models.Loan) error {
+		return func(context.Context, models.Event, *models.Loan) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls[name]++
+			if calls[name] <= failures {
+				return errors.New(name + " failed")
+			}
+			return nil
+		}
+	}
+
+	On(registry, models.EventTypeContractOpened, handler("ok", 0))
+	On(registry, models.EventTypeContractOpened, handler("flaky", 1))
+	On(registry, models.EventTypeContractOpened, handler("flakier", 2))
+
+	if err := registry.Dispatch(context.Background(), loanEvent(1, models.EventTypeContractOpened)); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	// Each handler is called until it succeeds, and not again after
+	want := map[string]int{"ok": 1, "flaky": 2, "flakier": 3}
+	for name, n := range want {
+		if calls[name] != n {
+			t.Errorf("%s handler called %d times, want %d", name, calls[name], n)
+		}
+	}
+
+	if records := readDeadLetters(t, deadLetterFile); len(records) != 0 {
+		t.Errorf("dead letters = %+v, want none", records)
+	}
+}
+
+func TestDispatchDeadLetter(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(calls *int) func(context.Context, models.Event, *models.Loan) error
+		loanStatus int
+		wantCalls  int
+		wantError  string
+	}{
+		{"handler keeps failing", func(calls *int) func(context.Context, models.Event, *models.Loan) error {
+			return func(context.Context, models.Event, *models.Loan) error {
+				*calls++
+				return errors.New("database is down")
+			}
+		}, http.StatusOK, 3, "database is down"},
+		{"entity cannot be fetched", func(calls *int) func(context.Context, models.Event, *models.Loan) error {
+			return func(context.Context, models.Event, *models.Loan) error {
+				*calls++
+				return nil
+			}
+		}, http.StatusNotFound, 0, "resolving /v1/ledger/loans/L1 of event [7]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := ledgertest.New(t)
+			if tt.loanStatus == http.StatusOK {
+				ledger.Set(ledgertest.Base+"loans/L1", models.Loan{LoanId: "L1"})
+			}
+			registry, deadLetterFile := newTestRegistry(t, ledger)
+
+			calls := 0
+			On(registry, models.EventTypeContractOpened, tt.handler(&calls))
+
+			event := loanEvent(7, models.EventTypeContractOpened)
+			if err := registry.Dispatch(context.Background(), event); err != nil {
+				t.Fatalf("Dispatch = %v, want the event dead-lettered instead", err)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+
+			records := readDeadLetters(t, deadLetterFile)
+			if len(records) != 1 {
+				t.Fatalf("dead letters = %+v, want one", records)
+			}
+
+			rec := records[0]
+			if rec.Event != event || rec.Attempts != testRetry.MaxAttempts || !strings.Contains(rec.Error, tt.wantError) || rec.FailedAt == "" {
+				t.Errorf("dead letter = %+v, want event [7] after %d attempts with %q", rec, testRetry.MaxAttempts, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestDispatchResolveRetried(t *testing.T) {
+	ledger := ledgertest.New(t)
+	registry, deadLetterFile := newTestRegistry(t, ledger)
+
+	// The loan is fetched on the second attempt; a 500 is not retried by
+	// the Client itself
+	var mu sync.Mutex
+	fetches := 0
+	ledger.Handle(ledgertest.Base+"loans/L1", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fetches++
+		if fetches == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(models.Loan{LoanId: "L1"})
+	})
+
+	calls := 0
+	On(registry, models.EventTypeContractOpened, func(context.Context, models.Event, *models.Loan) error {
+		calls++
+		return nil
+	})
+
+	if err := registry.Dispatch(context.Background(), loanEvent(1, models.EventTypeContractOpened)); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	if fetches != 2 || calls != 1 {
+		t.Errorf("loan fetched %d times and handled %d times, want 2 and 1", fetches, calls)
+	}
+
+	if records := readDeadLetters(t, deadLetterFile); len(records) != 0 {
+		t.Errorf("dead letters = %+v, want none", records)
+	}
+}
+
+func TestDispatchCanceled(t *testing.T) {
+	ledger := ledgertest.New(t)
+	ledger.Set(ledgertest.Base+"loans/L1", models.Loan{LoanId: "L1"})
+	registry, deadLetterFile := newTestRegistry(t, ledger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	On(registry, models.EventTypeContractOpened, func(context.Context, models.Event, *models.Loan) error {
+		cancel()
+		return errors.New("interrupted")
+	})
+
+	if err := registry.Dispatch(ctx, loanEvent(1, models.EventTypeContractOpened)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Dispatch = %v, want context.Canceled", err)
+	}
+
+	if records := readDeadLetters(t, deadLetterFile); len(records) != 0 {
+		t.Errorf("dead letters = %+v, want none for a canceled event", records)
+	}
+}
@@ -0,0 +1,313 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Headers of a webhook delivery
+const (
+	SignatureHeader = "X-1Source-Signature"
+	EventIdHeader   = "X-1Source-Event-Id"
+	EventTypeHeader = "X-1Source-Event-Type"
+)
+
+// DefaultDeliveryLogDir is where the delivery logs are written when the
+// [webhooks] section of the configuration TOML file sets no directory
+const DefaultDeliveryLogDir = "webhooks"
+
+// Delivery is the body POSTed to a webhook: the event and, when the relay
+// includes entities, the entity its resourceUri refers to
+type Delivery struct {
+	Event  models.Event `json:"event"`
+	Entity any          `json:"entity,omitempty"`
+}
+
+// DeliveryRecord is one line of the delivery log of a webhook URL
+type DeliveryRecord struct {
+	EventId    uint64           `json:"eventId"`
+	EventType  models.EventType `json:"eventType"`
+	Url        string           `json:"url"`
+	Delivered  bool             `json:"delivered"`
+	Attempts   int              `json:"attempts"`
+	StatusCode int              `json:"statusCode,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	Time       string           `json:"time"`
+}
+
+// Relay POSTs events to webhook URLs, so that internal systems get the
+// ledger events without holding ledger credentials. Each delivery is signed
+// with HMAC-SHA256 in the X-1Source-Signature header, retried per URL with
+// backoff and recorded in the delivery log of the URL. A URL which keeps
+// failing does not hold up the others, and holds up the events after it by
+// no more than its retries, whose delays are capped at the MaxDelay of the
+// retry policy even when the URL asks for a longer Retry-After
+type Relay struct {
+	client        *api.Client
+	urls          []string
+	secret        []byte
+	includeEntity bool
+	logDir        string
+	retry         api.RetryPolicy
+	httpClient    *http.Client
+
+	mu sync.Mutex
+}
+
+// NewRelay creates a Relay from the [webhooks] section of the configuration
+// TOML file. The client resolves the entities of the events; deliveries are
+// sent with a separate HTTP client which carries no ledger credentials
+func NewRelay(client *api.Client, cfg *models.AppConfig) (*Relay, error) {
+	hooks := cfg.Webhooks
+
+	if len(hooks.Urls) == 0 {
+		return nil, errors.New("urls is not set in the [webhooks] section of the configuration TOML file")
+	}
+
+	for _, u := range hooks.Urls {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid webhook url '%s'", u)
+		}
+	}
+
+	if hooks.Secret == "" {
+		return nil, errors.New("secret is not set in the [webhooks] section of the configuration TOML file")
+	}
+
+	logDir := hooks.Delivery_Log_Dir
+	if logDir == "" {
+		logDir = DefaultDeliveryLogDir
+	}
+
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating delivery log directory '%s': %w", logDir, err)
+	}
+
+	timeout := api.DefaultTimeout
+	if cfg.Connection.Timeout > 0 {
+		timeout = time.Duration(cfg.Connection.Timeout) * time.Second
+	}
+
+	return &Relay{
+		client:        client,
+		urls:          hooks.Urls,
+		secret:        []byte(hooks.Secret),
+		includeEntity: hooks.Include_Entity,
+		logDir:        logDir,
+		retry:         api.NewRetryPolicy(cfg),
+		httpClient:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Sign returns the value of the X-1Source-Signature header of a body:
+// "sha256=" followed by the hex HMAC-SHA256 of the body with the secret.
+// Receivers check a delivery by computing the same and comparing them with
+// hmac.Equal
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Handler returns a function handing events to Deliver, for a Follower
+func (r *Relay) Handler(ctx context.Context) func(models.Event) error {
+	return func(event models.Event) error {
+		return r.Deliver(ctx, event)
+	}
+}
+
+// Deliver POSTs an event to every webhook URL concurrently. A URL which
+// still fails after the last attempt is recorded in its delivery log and is
+// not an error; only a canceled ctx or a delivery log which cannot be
+// written stop the relay
+func (r *Relay) Deliver(ctx context.Context, event models.Event) error {
+	delivery := Delivery{Event: event}
+
+	if r.includeEntity {
+		entity, err := Resolve(ctx, r.client, event)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Relaying event [%d] without its entity: %s", event.EventId, err)
+		} else {
+			delivery.Entity = entity
+		}
+	}
+
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("encoding event [%d]: %w", event.EventId, err)
+	}
+
+	errs := make([]error, len(r.urls))
+
+	var wg sync.WaitGroup
+	for i, u := range r.urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			errs[i] = r.record(r.deliverTo(ctx, u, event, body))
+		}(i, u)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliverTo POSTs a delivery to one URL, retrying failures with backoff
+func (r *Relay) deliverTo(ctx context.Context, u string, event models.Event, body []byte) DeliveryRecord {
+	rec := DeliveryRecord{EventId: event.EventId, EventType: event.EventType, Url: u}
+	attempts := max(r.retry.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		rec.Attempts = attempt
+
+		status, err := r.post(ctx, u, event, body)
+		rec.StatusCode = status
+
+		if err == nil {
+			rec.Delivered = true
+			rec.Error = ""
+			return rec
+		}
+		rec.Error = err.Error()
+
+		// Other client errors will fail the same way again
+		retryable := status == 0 || status == http.StatusTooManyRequests || status >= 500
+		if !retryable || attempt >= attempts || ctx.Err() != nil {
+			log.Printf("Error relaying event [%d] to %s after %d attempts: %s", event.EventId, u, attempt, err)
+			return rec
+		}
+
+		log.Printf("Retrying event [%d] to %s (attempt %d of %d): %s", event.EventId, u, attempt+1, attempts, err)
+
+		// A 429 or 503 may say when to come back in its Retry-After
+		if r.retry.Wait(ctx, attempt, err) != nil {
+			return rec
+		}
+	}
+}
+
+// WebhookError is returned for a non-2xx response of a webhook. RetryAfter
+// holds its Retry-After header, if it sent one
+type WebhookError struct {
+	Url        string
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook %s responded %s", e.Url, e.Status)
+}
+
+// RetryAfterDelay returns the webhook's Retry-After, implementing
+// api.RetryAfterError
+func (e *WebhookError) RetryAfterDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// post sends one signed delivery, returning the HTTP status code if any. A
+// non-2xx response is returned as *WebhookError
+func (r *Relay) post(ctx context.Context, u string, event models.Event, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, Sign(r.secret, body))
+	request.Header.Set(EventIdHeader, strconv.FormatUint(event.EventId, 10))
+	request.Header.Set(EventTypeHeader, string(event.EventType))
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	// Drain the body so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, &WebhookError{
+			Url:        u,
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			RetryAfter: api.ParseRetryAfter(response.Header.Get("Retry-After")),
+		}
+	}
+
+	return response.StatusCode, nil
+}
+
+// record appends a delivery record to the delivery log of its URL
+func (r *Relay) record(rec DeliveryRecord) error {
+	rec.Time = time.Now().UTC().Format(time.RFC3339)
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	filename := r.LogFile(rec.Url)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("writing delivery log '%s': %w", filename, err)
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing delivery log '%s': %w", filename, err)
+	}
+
+	return nil
+}
+
+// LogFile returns the delivery log of a URL: a file named after its host,
+// made unique by a hash of the whole URL
+func (r *Relay) LogFile(u string) string {
+	host := "webhook"
+	if parsed, err := url.Parse(u); err == nil && parsed.Host != "" {
+		host = strings.NewReplacer(":", "_", "/", "_").Replace(parsed.Host)
+	}
+
+	sum := sha256.Sum256([]byte(u))
+
+	return filepath.Join(r.logDir, host+"-"+hex.EncodeToString(sum[:4])+".ndjson")
+}
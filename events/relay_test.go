@@ -0,0 +1,331 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+const testSecret = "webhook_secret"
+
+var testEvent = models.Event{
+	EventId:       10012358,
+	EventType:     models.EventTypeTrade,
+	EventDateTime: "2023-11-02T13:42:16.049Z",
+	ResourceUri:   "/v1/ledger/agreements/2cf9d8cc-2b77-49bf-8bb2-9956aaf9cf97",
+}
+
+// newTestRelay creates a Relay to the given URLs with short retry delays
+// and its delivery logs in a temporary directory
+func newTestRelay(t *testing.T, urls ...string) *Relay {
+	t.Helper()
+
+	cfg := &models.AppConfig{}
+	cfg.Webhooks.Urls = urls
+	cfg.Webhooks.Secret = testSecret
+	cfg.Webhooks.Delivery_Log_Dir = t.TempDir()
+	cfg.Retry.Max_Attempts = 3
+	cfg.Retry.Base_Delay_Ms = 1
+	cfg.Retry.Max_Delay_Ms = 5
+
+	relay, err := NewRelay(nil, cfg)
+	if err != nil {
+		t.Fatalf("NewRelay: %v", err)
+	}
+
+	return relay
+}
+
+// readDeliveryLog reads the delivery records of a URL
+func readDeliveryLog(t *testing.T, relay *Relay, u string) []DeliveryRecord {
+	t.Helper()
+
+	file, err := os.Open(relay.LogFile(u))
+	if err != nil {
+		t.Fatalf("opening delivery log of %s: %v", u, err)
+	}
+	defer file.Close()
+
+	var records []DeliveryRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec DeliveryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("decoding delivery record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+
+	return records
+}
+
+func TestDeliverSignature(t *testing.T) {
+	var got struct {
+		sync.Mutex
+		body      []byte
+		signature string
+		eventId   string
+		eventType string
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		got.Lock()
+		defer got.Unlock()
+		got.body = body
+		got.signature = r.Header.Get(SignatureHeader)
+		got.eventId = r.Header.Get(EventIdHeader)
+		got.eventType = r.Header.Get(EventTypeHeader)
+	}))
+	defer server.Close()
+
+	relay := newTestRelay(t, server.URL)
+	if err := relay.Deliver(context.Background(), testEvent); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	got.Lock()
+	defer got.Unlock()
+
+	// The receiver computes the signature of the body it got with the shared
+	// secret and compares them in constant time
+	want := Sign([]byte(testSecret), got.body)
+	if !hmac.Equal([]byte(got.signature), []byte(want)) {
+		t.Errorf("signature %q does not verify, want %q", got.signature, want)
+	}
+
+	if wrong := Sign([]byte("other_secret"), got.body); hmac.Equal([]byte(got.signature), []byte(wrong)) {
+		t.Errorf("signature verifies with the wrong secret")
+	}
+
+	if got.eventId != strconv.FormatUint(testEvent.EventId, 10) || got.eventType != string(testEvent.EventType) {
+		t.Errorf("event headers = %q, %q", got.eventId, got.eventType)
+	}
+
+	var delivery Delivery
+	if err := json.Unmarshal(got.body, &delivery); err != nil || delivery.Event != testEvent {
+		t.Errorf("delivery body = %s (%v), want event %+v", got.body, err, testEvent)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantOK       bool
+		wantStatus   int
+	}{
+		{"delivered first time", []int{200}, 1, true, 200},
+		{"5xx retried", []int{503, 502, 204}, 3, true, 204},
+		{"429 retried", []int{429, 200}, 2, true, 200},
+		{"4xx not retried", []int{400}, 1, false, 400},
+		{"404 not retried", []int{404}, 1, false, 404},
+		{"gives up after the last attempt", []int{500, 500, 500, 200}, 3, false, 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				status := tt.statuses[min(calls, len(tt.statuses)-1)]
+				calls++
+				mu.Unlock()
+
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			relay := newTestRelay(t, server.URL)
+			if err := relay.Deliver(context.Background(), testEvent); err != nil {
+				t.Fatalf("Deliver: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if calls != tt.wantAttempts {
+				t.Errorf("webhook called %d times, want %d", calls, tt.wantAttempts)
+			}
+
+			records := readDeliveryLog(t, relay, server.URL)
+			if len(records) != 1 {
+				t.Fatalf("got %d delivery records, want 1", len(records))
+			}
+
+			rec := records[0]
+			if rec.Delivered != tt.wantOK || rec.Attempts != tt.wantAttempts || rec.StatusCode != tt.wantStatus {
+				t.Errorf("record = %+v, want delivered %v after %d attempts with status %d", rec, tt.wantOK, tt.wantAttempts, tt.wantStatus)
+			}
+			if rec.EventId != testEvent.EventId || rec.Url != server.URL {
+				t.Errorf("record = %+v, want event [%d] to %s", rec, testEvent.EventId, server.URL)
+			}
+		})
+	}
+}
+
+func TestDeliverFailingURLDoesNotBlockOthers(t *testing.T) {
+	goodHit := make(chan struct{})
+	var once sync.Once
+
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(goodHit) })
+	}))
+	defer good.Close()
+
+	// The failing URL only answers once the good one has been delivered to,
+	// which never happens if the deliveries wait for each other
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-goodHit:
+		case <-time.After(2 * time.Second):
+			t.Error("delivery to the good URL was held up by the failing one")
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+
+	relay := newTestRelay(t, bad.URL, good.URL)
+	if err := relay.Deliver(context.Background(), testEvent); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	if rec := readDeliveryLog(t, relay, good.URL); len(rec) != 1 || !rec[0].Delivered {
+		t.Errorf("good URL records = %+v, want one delivered", rec)
+	}
+
+	if rec := readDeliveryLog(t, relay, bad.URL); len(rec) != 1 || rec[0].Delivered || rec[0].Attempts != 3 {
+		t.Errorf("failing URL records = %+v, want one failed after 3 attempts", rec)
+	}
+
+	if relay.LogFile(good.URL) == relay.LogFile(bad.URL) {
+		t.Errorf("both URLs log to %s", relay.LogFile(good.URL))
+	}
+}
+
+func TestDeliveryLogPerURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	urls := []string{server.URL + "/a", server.URL + "/b"}
+	relay := newTestRelay(t, urls...)
+
+	events := []models.Event{testEvent, {EventId: testEvent.EventId + 1, EventType: models.EventTypeTrade}}
+	for _, e := range events {
+		if err := relay.Deliver(context.Background(), e); err != nil {
+			t.Fatalf("Deliver: %v", err)
+		}
+	}
+
+	for _, u := range urls {
+		records := readDeliveryLog(t, relay, u)
+		if len(records) != len(events) {
+			t.Fatalf("%s has %d delivery records, want %d", u, len(records), len(events))
+		}
+
+		for i, rec := range records {
+			if rec.Url != u || rec.EventId != events[i].EventId || !rec.Delivered || rec.Time == "" {
+				t.Errorf("%s record %d = %+v", u, i, rec)
+			}
+		}
+	}
+}
+
+func TestWebhookRetryAfter(t *testing.T) {
+	policy := api.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{"honored", 7 * time.Second, 7 * time.Second},
+		{"capped at MaxDelay", 24 * time.Hour, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		err := &WebhookError{StatusCode: http.StatusTooManyRequests, RetryAfter: tt.retryAfter}
+		if got := policy.Delay(1, err); got != tt.want {
+			t.Errorf("%s: Delay = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if got := policy.Delay(1, &WebhookError{StatusCode: http.StatusServiceUnavailable}); got > time.Millisecond {
+		t.Errorf("Delay = %s without Retry-After, want at most the 1ms backoff", got)
+	}
+}
+
+func TestDeliverLongRetryAfterDoesNotHoldUpNextEvent(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+
+	// One receiver asks to come back in a day, the other takes every event
+	busy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer busy.Close()
+
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.Header.Get(EventIdHeader))
+		mu.Unlock()
+	}))
+	defer good.Close()
+
+	relay := newTestRelay(t, busy.URL, good.URL)
+	next := testEvent
+	next.EventId++
+
+	done := make(chan error, 1)
+	go func() {
+		for _, e := range []models.Event{testEvent, next} {
+			if err := relay.Deliver(context.Background(), e); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Deliver: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a Retry-After of a day held up the next event")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []string{strconv.FormatUint(testEvent.EventId, 10), strconv.FormatUint(next.EventId, 10)}
+	if !slices.Equal(received, want) {
+		t.Errorf("good URL received events %v, want %v", received, want)
+	}
+
+	if rec := readDeliveryLog(t, relay, busy.URL); len(rec) != 2 || rec[0].Delivered || rec[0].Attempts != 3 || rec[0].StatusCode != http.StatusTooManyRequests {
+		t.Errorf("busy URL records = %+v, want two failed after 3 attempts with 429", rec)
+	}
+}
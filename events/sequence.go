@@ -0,0 +1,277 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// MaxFetchIds bounds the number of missing events fetched one by one, by
+// EventId, once listing the events around the gaps again has not found them
+const MaxFetchIds = 100
+
+// Gap is a range of missing EventIds, From and To included
+type Gap struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// Len returns the number of EventIds in the gap
+func (g Gap) Len() uint64 {
+	return g.To - g.From + 1
+}
+
+// String returns the gap as 'from-to', or the EventId of a gap of one
+func (g Gap) String() string {
+	if g.From == g.To {
+		return fmt.Sprint(g.From)
+	}
+
+	return fmt.Sprintf("%d-%d", g.From, g.To)
+}
+
+// SequenceReport describes the EventIds of a list of events. EventIds
+// increase monotonically, so the list should hold each EventId after the
+// last one already seen once, in order. Gaps are the EventIds missing from
+// the list as received, of which Recovered were found by fetching them
+// again and Missing were not
+type SequenceReport struct {
+	Received   int      `json:"received"`
+	Duplicates []uint64 `json:"duplicates,omitempty"`
+	OutOfOrder []uint64 `json:"outOfOrder,omitempty"`
+	Gaps       []Gap    `json:"gaps,omitempty"`
+	Recovered  []uint64 `json:"recovered,omitempty"`
+	Missing    []Gap    `json:"missing,omitempty"`
+}
+
+// CheckSequence checks the EventIds of events as received, ignoring those
+// at or before after. The events are expected in ascending EventId order,
+// or descending if the first one comes after the last one. Without after,
+// gaps are only looked for between the first and the last EventId
+func CheckSequence(events models.Events, after uint64) *SequenceReport {
+	var ids []uint64
+	for _, e := range events {
+		if e.EventId > after {
+			ids = append(ids, e.EventId)
+		}
+	}
+
+	report := &SequenceReport{Received: len(ids)}
+	if len(ids) == 0 {
+		return report
+	}
+
+	descending := ids[0] > ids[len(ids)-1]
+	seen := make(map[uint64]bool, len(ids))
+	var prev uint64
+
+	for i, id := range ids {
+		if seen[id] {
+			report.Duplicates = append(report.Duplicates, id)
+			continue
+		}
+		seen[id] = true
+
+		if i > 0 && (descending && id > prev || !descending && id < prev) {
+			report.OutOfOrder = append(report.OutOfOrder, id)
+		}
+		prev = id
+	}
+
+	report.Gaps = gaps(seen, after)
+	report.Missing = slices.Clone(report.Gaps)
+
+	return report
+}
+
+// gaps returns the EventIds missing between after, or the first EventId if
+// after is zero, and the last EventId of a set
+func gaps(ids map[uint64]bool, after uint64) []Gap {
+	sorted := make([]uint64, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	slices.Sort(sorted)
+
+	if len(sorted) == 0 {
+		return nil
+	}
+
+	next := after + 1
+	if after == 0 {
+		next = sorted[0]
+	}
+
+	var found []Gap
+	for _, id := range sorted {
+		if id > next {
+			found = append(found, Gap{From: next, To: id - 1})
+		}
+		next = id + 1
+	}
+
+	return found
+}
+
+// Clean reports whether the events were received without duplicates or
+// gaps and in order
+func (r *SequenceReport) Clean() bool {
+	return len(r.Duplicates) == 0 && len(r.OutOfOrder) == 0 && len(r.Gaps) == 0
+}
+
+// MissingCount returns the number of EventIds still missing
+func (r *SequenceReport) MissingCount() uint64 {
+	var n uint64
+	for _, g := range r.Missing {
+		n += g.Len()
+	}
+
+	return n
+}
+
+// Add adds the findings of another report to this one
+func (r *SequenceReport) Add(other *SequenceReport) {
+	r.Received += other.Received
+	r.Duplicates = append(r.Duplicates, other.Duplicates...)
+	r.OutOfOrder = append(r.OutOfOrder, other.OutOfOrder...)
+	r.Gaps = append(r.Gaps, other.Gaps...)
+	r.Recovered = append(r.Recovered, other.Recovered...)
+	r.Missing = append(r.Missing, other.Missing...)
+}
+
+// String summarizes the report on one line
+func (r *SequenceReport) String() string {
+	s := fmt.Sprintf("%d events received, %d duplicate, %d out of order, %d in gaps, %d recovered, %d missing",
+		r.Received, len(r.Duplicates), len(r.OutOfOrder), gapLen(r.Gaps), len(r.Recovered), r.MissingCount())
+
+	if len(r.Missing) > 0 {
+		missing := make([]string, len(r.Missing))
+		for i, g := range r.Missing {
+			missing[i] = g.String()
+		}
+		s += " [" + strings.Join(missing, ", ") + "]"
+	}
+
+	return s
+}
+
+// gapLen returns the number of EventIds in gaps
+func gapLen(gaps []Gap) uint64 {
+	var n uint64
+	for _, g := range gaps {
+		n += g.Len()
+	}
+
+	return n
+}
+
+// List lists the events after an EventId and checks their sequence. Gaps
+// are re-fetched, first by listing the events between the ones on either
+// side of the gaps again, which finds events committed or moved while the
+// pages were fetched, then one by one by EventId if no more than
+// MaxFetchIds are left. The events are returned in EventId order without
+// duplicates, along with the report of what was received and what is still
+// missing
+func List(ctx context.Context, client *api.Client, opts api.ListOptions, after uint64) (models.Events, *SequenceReport, error) {
+	events, err := client.ListEvents(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := CheckSequence(events, after)
+
+	events = slices.DeleteFunc(events, func(e models.Event) bool { return e.EventId <= after })
+	SortEvents(events)
+	events = slices.CompactFunc(events, func(a, b models.Event) bool { return a.EventId == b.EventId })
+
+	if len(report.Gaps) == 0 {
+		return events, report, nil
+	}
+
+	missing := func(id uint64) bool {
+		for _, g := range report.Missing {
+			if id >= g.From && id <= g.To {
+				return true
+			}
+		}
+		return false
+	}
+
+	fill := func(found models.Events) {
+		for _, e := range found {
+			if missing(e.EventId) && !slices.ContainsFunc(events, func(f models.Event) bool { return f.EventId == e.EventId }) {
+				events = append(events, e)
+				report.Recovered = append(report.Recovered, e.EventId)
+			}
+		}
+
+		SortEvents(events)
+		report.Missing = gaps(idSet(events), after)
+	}
+
+	relisted, err := client.ListEvents(ctx, gapWindow(events, report.Gaps, opts))
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing events again for gaps: %w", err)
+	}
+	fill(relisted)
+
+	if n := report.MissingCount(); n > 0 && n <= MaxFetchIds {
+		var fetched models.Events
+		for _, g := range report.Missing {
+			for id := g.From; id <= g.To; id++ {
+				e, err := client.GetEvent(ctx, id)
+				if api.IsNotFound(err) {
+					continue
+				}
+				if err != nil {
+					return nil, nil, fmt.Errorf("fetching event [%d]: %w", id, err)
+				}
+				fetched = append(fetched, *e)
+			}
+		}
+		fill(fetched)
+	}
+
+	slices.Sort(report.Recovered)
+
+	return events, report, nil
+}
+
+// idSet returns the EventIds of events
+func idSet(events models.Events) map[uint64]bool {
+	ids := make(map[uint64]bool, len(events))
+	for _, e := range events {
+		ids[e.EventId] = true
+	}
+
+	return ids
+}
+
+// gapWindow returns the list options to list the events of gaps again:
+// from the time of the event before the first gap to just after the time of
+// the event after the last one, within the time range of opts
+func gapWindow(events models.Events, found []Gap, opts api.ListOptions) api.ListOptions {
+	window := api.ListOptions{Size: opts.Size, Since: opts.Since, Before: opts.Before}
+
+	for _, e := range events {
+		t, err := time.Parse(time.RFC3339, e.EventDateTime)
+		if err != nil {
+			continue
+		}
+
+		if e.EventId == found[0].From-1 && t.After(window.Since) {
+			window.Since = t
+		}
+
+		if e.EventId == found[len(found)-1].To+1 && (window.Before.IsZero() || t.Before(window.Before)) {
+			window.Before = t.Add(time.Second)
+		}
+	}
+
+	return window
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// eventsOf returns events with the given EventIds, in that order
+func eventsOf(ids ...uint64) models.Events {
+	events := make(models.Events, len(ids))
+	for i, id := range ids {
+		events[i] = models.Event{EventId: id}
+	}
+
+	return events
+}
+
+func TestCheckSequence(t *testing.T) {
+	tests := []struct {
+		name       string
+		ids        []uint64
+		after      uint64
+		received   int
+		duplicates []uint64
+		outOfOrder []uint64
+		gaps       []Gap
+	}{
+		{"empty", nil, 0, 0, nil, nil, nil},
+		{"all at or before after", []uint64{3, 4}, 4, 0, nil, nil, nil},
+		{"ascending", []uint64{1, 2, 3}, 0, 3, nil, nil, nil},
+		{"ascending with gaps", []uint64{1, 2, 5, 7}, 0, 4, nil, nil, []Gap{{3, 4}, {6, 6}}},
+		{"descending", []uint64{7, 6, 5}, 0, 3, nil, nil, nil},
+		{"descending with a gap", []uint64{9, 8, 5}, 0, 3, nil, nil, []Gap{{6, 7}}},
+		{"descending out of order", []uint64{9, 6, 8, 5}, 0, 4, nil, []uint64{8}, []Gap{{7, 7}}},
+		{"ascending out of order", []uint64{1, 3, 2, 4}, 0, 4, nil, []uint64{2}, nil},
+		{"duplicates", []uint64{1, 2, 2, 3, 1}, 0, 5, []uint64{2, 1}, nil, nil},
+		{"descending duplicates", []uint64{3, 3, 2, 1}, 0, 4, []uint64{3}, nil, nil},
+		{"after zero starts at the first event", []uint64{5, 6}, 0, 2, nil, nil, nil},
+		{"gap right after after", []uint64{5, 6}, 2, 2, nil, nil, []Gap{{3, 4}}},
+		{"events at or before after ignored", []uint64{1, 2, 3, 5}, 2, 2, nil, nil, []Gap{{4, 4}}},
+		{"descending after after", []uint64{6, 5, 2}, 3, 2, nil, nil, []Gap{{4, 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckSequence(eventsOf(tt.ids...), tt.after)
+
+			if report.Received != tt.received {
+				t.Errorf("Received = %d, want %d", report.Received, tt.received)
+			}
+			if !slices.Equal(report.Duplicates, tt.duplicates) {
+				t.Errorf("Duplicates = %v, want %v", report.Duplicates, tt.duplicates)
+			}
+			if !slices.Equal(report.OutOfOrder, tt.outOfOrder) {
+				t.Errorf("OutOfOrder = %v, want %v", report.OutOfOrder, tt.outOfOrder)
+			}
+			if !slices.Equal(report.Gaps, tt.gaps) || !slices.Equal(report.Missing, tt.gaps) {
+				t.Errorf("Gaps = %v, Missing = %v, want %v", report.Gaps, report.Missing, tt.gaps)
+			}
+
+			clean := len(tt.duplicates) == 0 && len(tt.outOfOrder) == 0 && len(tt.gaps) == 0
+			if report.Clean() != clean {
+				t.Errorf("Clean() = %v, want %v", report.Clean(), clean)
+			}
+		})
+	}
+}
+
+func TestSequenceReportString(t *testing.T) {
+	report := CheckSequence(eventsOf(1, 2, 2, 5, 4, 9), 0)
+	report.Recovered = []uint64{3}
+	report.Missing = []Gap{{6, 8}}
+
+	if n := report.MissingCount(); n != 3 {
+		t.Errorf("MissingCount() = %d, want 3", n)
+	}
+
+	want := "6 events received, 1 duplicate, 1 out of order, 4 in gaps, 1 recovered, 3 missing [6-8]"
+	if got := report.String(); got != want {
+		t.Errorf("String() =\n%q, want\n%q", got, want)
+	}
+
+	total := &SequenceReport{}
+	total.Add(report)
+	total.Add(CheckSequence(eventsOf(11, 13), 10))
+	if total.Received != 8 || total.MissingCount() != 4 || len(total.Gaps) != 3 {
+		t.Errorf("Add gives %s", total)
+	}
+}
+
+func TestGapString(t *testing.T) {
+	tests := []struct {
+		gap  Gap
+		want string
+		len  uint64
+	}{
+		{Gap{7, 7}, "7", 1},
+		{Gap{3, 5}, "3-5", 3},
+	}
+
+	for _, tt := range tests {
+		if got := tt.gap.String(); got != tt.want || tt.gap.Len() != tt.len {
+			t.Errorf("%+v: String() = %q, Len() = %d, want %q and %d", tt.gap, got, tt.gap.Len(), tt.want, tt.len)
+		}
+	}
+}
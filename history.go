@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/EquiLend/1Source-Go/utils"
+)
+
+// loanHistory is the JSON output of a loan's history
+type loanHistory struct {
+	LoanId   string               `json:"loanId"`
+	Versions []models.LoanVersion `json:"versions"`
+}
+
+// printLoanHistory prints the versions of a loan_id and the fields each one
+// changed, as text or JSON, or the raw history payload
+func printLoanHistory(ctx context.Context, loanId string, options []string) {
+	fs := flag.NewFlagSet("-lh", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the versions and their changes as JSON")
+	raw := fs.Bool("raw", false, "print the history as returned by the 1Source REST API")
+	parseOptions(fs, options)
+
+	prompt := fmt.Sprintf("Error retrieving Loan History with loan_id = [%s]: ", loanId)
+
+	if *raw {
+		header := "1Source Loan History"
+		endPoint := appConfig.Endpoints.Loans + "/" + loanId + "/history"
+		history, err := client.GetEntity(ctx, endPoint, header)
+		utils.PrintResults(err, history, prompt, header)
+		return
+	}
+
+	history, err := client.GetLoanHistory(ctx, loanId)
+	if err == nil && len(history) == 0 {
+		err = fmt.Errorf("no versions of loan [%s]", loanId)
+	}
+
+	var versions []models.LoanVersion
+	if err == nil {
+		versions, err = history.Versions()
+	}
+
+	if err != nil {
+		utils.PrintResults(err, "", prompt, "")
+		log.Println(prompt, err)
+		return
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(loanHistory{LoanId: loanId, Versions: versions}, "", "  ")
+		if err != nil {
+			fmt.Println("Error formatting loan history: ", err)
+			return
+		}
+
+		fmt.Println(string(data))
+		return
+	}
+
+	header := fmt.Sprintf("1Source Loan History of [%s], %d versions", loanId, len(versions))
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("=", len(header)))
+
+	for _, v := range versions {
+		fmt.Printf("\nEvent %d at %s by %s: %s\n", v.EventId, orUnknown(v.UpdateDateTime), orUnknown(v.UpdatePartyId), v.LoanStatus)
+
+		if len(v.Changes) == 0 {
+			fmt.Println("  No fields changed")
+			continue
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  FIELD\tBEFORE\tAFTER")
+		for _, c := range v.Changes {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", c.Field, c.Before, c.After)
+		}
+		w.Flush()
+	}
+}
+
+// orUnknown returns value, or 'unknown' if it is empty
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
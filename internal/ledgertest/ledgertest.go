@@ -0,0 +1,165 @@
+// Package ledgertest serves a fake 1Source REST API, along with the KeyCloak
+// token endpoint it is logged into with, for the tests of the packages built
+// on the api package:
+//
+//	ledger := ledgertest.New(t)
+//	ledger.Set("/v1/ledger/loans/L1", loan)
+//	client := ledger.Client(t)
+//
+// Resources are served as JSON by path, whatever the query parameters, and
+// a path without a resource answers 404
+package ledgertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Base is the path of the 1Source REST API on the fake ledger
+const Base = "/v1/ledger/"
+
+// realm is the KeyCloak realm of the fake ledger
+const realm = "test"
+
+// Ledger is a fake 1Source REST API
+type Ledger struct {
+	Server *httptest.Server
+
+	mu        sync.Mutex
+	resources map[string]any
+	handlers  map[string]http.HandlerFunc
+	requests  []string
+}
+
+// New starts a fake ledger, closed when the test ends
+func New(t *testing.T) *Ledger {
+	t.Helper()
+
+	l := &Ledger{resources: map[string]any{}, handlers: map[string]http.HandlerFunc{}}
+	l.Server = httptest.NewServer(http.HandlerFunc(l.serve))
+	t.Cleanup(l.Server.Close)
+
+	return l
+}
+
+// Set serves v as the JSON resource at a path
+func (l *Ledger) Set(path string, v any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.resources[path] = v
+}
+
+// Delete removes the resource at a path, which then answers 404
+func (l *Ledger) Delete(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.resources, path)
+}
+
+// Handle serves a path with handler, taking precedence over its resource
+func (l *Ledger) Handle(path string, handler http.HandlerFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.handlers[path] = handler
+}
+
+// Requests returns the requests served so far, as "METHOD path"
+func (l *Ledger) Requests() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.requests...)
+}
+
+// Config returns a configuration pointing at the fake ledger, for party
+// partyId, with short retry delays
+func (l *Ledger) Config(partyId string) *models.AppConfig {
+	url := l.Server.URL + Base
+
+	cfg := &models.AppConfig{}
+	cfg.General.Auth_URL = l.Server.URL
+	cfg.General.Realm_Name = realm
+	cfg.General.Party_Id = partyId
+	cfg.Endpoints.Base = url
+	cfg.Endpoints.Parties = url + "parties"
+	cfg.Endpoints.Events = url + "events"
+	cfg.Endpoints.Agreements = url + "agreements"
+	cfg.Endpoints.Loans = url + "loans"
+	cfg.Endpoints.Rerates = url + "rerates"
+	cfg.Endpoints.Returns = url + "returns"
+	cfg.Endpoints.Recalls = url + "recalls"
+	cfg.Endpoints.Buyins = url + "buyins"
+	cfg.Authentication.Auth_Type = api.AuthTypePublic
+	cfg.Authentication.Client_Id = "client"
+	cfg.Authentication.Username = "user"
+	cfg.Authentication.Password = "password"
+	cfg.Retry.Max_Attempts = 3
+	cfg.Retry.Base_Delay_Ms = 1
+	cfg.Retry.Max_Delay_Ms = 5
+
+	return cfg
+}
+
+// Client returns a Client of the fake ledger for party TLEN-US
+func (l *Ledger) Client(t *testing.T) *api.Client {
+	t.Helper()
+
+	return l.ClientOf(t, l.Config("TLEN-US"))
+}
+
+// ClientOf returns a Client of the fake ledger with a configuration from
+// Config
+func (l *Ledger) ClientOf(t *testing.T, cfg *models.AppConfig) *api.Client {
+	t.Helper()
+
+	tokens, err := api.NewTokenSource(cfg)
+	if err != nil {
+		t.Fatalf("NewTokenSource: %v", err)
+	}
+
+	client, err := api.NewClient(cfg, tokens)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	return client
+}
+
+// serve answers a request to the fake ledger
+func (l *Ledger) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/realms/"+realm+"/protocol/openid-connect/token" {
+		writeJSON(w, http.StatusOK, map[string]any{"access_token": "token", "expires_in": 300})
+		return
+	}
+
+	l.mu.Lock()
+	l.requests = append(l.requests, r.Method+" "+r.URL.Path)
+	handler, handled := l.handlers[r.URL.Path]
+	resource, found := l.resources[r.URL.Path]
+	l.mu.Unlock()
+
+	switch {
+	case handled:
+		handler(w, r)
+	case found && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, resource)
+	default:
+		writeJSON(w, http.StatusNotFound, models.LedgerResponse{Message: "not found"})
+	}
+}
+
+// writeJSON writes v as the JSON body of a response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
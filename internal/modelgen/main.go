@@ -0,0 +1,320 @@
+// Command modelgen generates the Go models of the 1Source REST API from the
+// checked-in copy of the 1Source OpenAPI document. It is run by go generate
+// in the models package:
+//
+//	go generate ./models
+//
+// Every schema under components/schemas becomes a Go type, in the order of
+// the document: string enums become named string types with one constant per
+// value, arrays become named slices and objects become structs. Optional
+// object and integer fields are pointers and optional fields are tagged
+// omitempty, so that request bodies built from the models only carry what
+// was set, and an absent integer can be told from a zero one.
+// A schema property may override its Go type with the x-go-type extension.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// schema is the subset of an OpenAPI schema object used by the generator
+type schema struct {
+	Ref         string          `json:"$ref"`
+	Type        string          `json:"type"`
+	Format      string          `json:"format"`
+	Description string          `json:"description"`
+	Enum        []string        `json:"enum"`
+	Items       *schema         `json:"items"`
+	Required    []string        `json:"required"`
+	Properties  json.RawMessage `json:"properties"`
+	GoType      string          `json:"x-go-type"`
+}
+
+// document is the subset of an OpenAPI document used by the generator
+type document struct {
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Components struct {
+		Schemas json.RawMessage `json:"schemas"`
+	} `json:"components"`
+}
+
+// generator holds the schemas of the document, keyed and in document order
+type generator struct {
+	names   []string
+	schemas map[string]*schema
+	buf     bytes.Buffer
+}
+
+func main() {
+	specPath := flag.String("spec", "", "path of the OpenAPI document (JSON)")
+	outPath := flag.String("out", "", "path of the generated Go file")
+	pkg := flag.String("package", "models", "package name of the generated Go file")
+	flag.Parse()
+
+	if *specPath == "" || *outPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := generate(*specPath, *pkg)
+	if err != nil {
+		log.Fatalf("modelgen: %s", err)
+	}
+
+	if err := os.WriteFile(*outPath, src, 0644); err != nil {
+		log.Fatalf("modelgen: %s", err)
+	}
+}
+
+// generate reads the OpenAPI document and returns the formatted Go source
+func generate(specPath string, pkg string) ([]byte, error) {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", specPath, err)
+	}
+
+	names, raw, err := orderedObject(doc.Components.Schemas)
+	if err != nil {
+		return nil, fmt.Errorf("decoding components/schemas: %w", err)
+	}
+
+	g := &generator{names: names, schemas: map[string]*schema{}}
+	for _, name := range names {
+		var s schema
+		if err := json.Unmarshal(raw[name], &s); err != nil {
+			return nil, fmt.Errorf("decoding schema %s: %w", name, err)
+		}
+		g.schemas[name] = &s
+	}
+
+	g.printf("// Code generated by modelgen from %s; DO NOT EDIT.\n\n", filepath.ToSlash(specPath))
+	g.printf("// %s %s\n\n", doc.Info.Title, doc.Info.Version)
+	g.printf("package %s\n", pkg)
+
+	for _, name := range names {
+		if err := g.emit(name, g.schemas[name]); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+// emit writes the Go declaration of one schema
+func (g *generator) emit(name string, s *schema) error {
+	g.printf("\n")
+	g.comment("", name, s.Description)
+
+	switch {
+	case len(s.Enum) > 0:
+		if s.Type != "string" {
+			return fmt.Errorf("unsupported %s enum", s.Type)
+		}
+		g.printf("type %s string\n\n", name)
+		g.printf("// %s values\nconst (\n", name)
+		for _, value := range s.Enum {
+			g.printf("\t%s%s %s = %q\n", name, camel(value), name, value)
+		}
+		g.printf(")\n")
+
+	case s.Type == "array":
+		elem, err := g.goType(s, true)
+		if err != nil {
+			return err
+		}
+		g.printf("type %s %s\n", name, elem)
+
+	case s.Type == "object":
+		props, raw, err := orderedObject(s.Properties)
+		if err != nil {
+			return fmt.Errorf("decoding properties: %w", err)
+		}
+
+		g.printf("type %s struct {\n", name)
+		for _, prop := range props {
+			var p schema
+			if err := json.Unmarshal(raw[prop], &p); err != nil {
+				return fmt.Errorf("decoding property %s: %w", prop, err)
+			}
+
+			required := slices.Contains(s.Required, prop)
+			typ, err := g.goType(&p, required)
+			if err != nil {
+				return fmt.Errorf("property %s: %w", prop, err)
+			}
+
+			tag := prop
+			if !required {
+				tag += ",omitempty"
+			}
+
+			g.comment("\t", "", p.Description)
+			g.printf("\t%s %s `json:%q`\n", exported(prop), typ, tag)
+		}
+		g.printf("}\n")
+
+	default:
+		typ, err := g.goType(s, true)
+		if err != nil {
+			return err
+		}
+		g.printf("type %s %s\n", name, typ)
+	}
+
+	return nil
+}
+
+// goType returns the Go type of a schema. Optional references to object
+// schemas and optional integers are pointers
+func (g *generator) goType(s *schema, required bool) (string, error) {
+	if s.GoType != "" {
+		return s.GoType, nil
+	}
+
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		target, ok := g.schemas[name]
+		if !ok {
+			return "", fmt.Errorf("unknown reference %s", s.Ref)
+		}
+		if !required && target.Type == "object" {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+
+	switch s.Type {
+	case "string":
+		return "string", nil
+	case "boolean":
+		return "bool", nil
+	case "integer":
+		typ := "int64"
+		if s.Format == "int32" {
+			typ = "int32"
+		}
+		if !required {
+			typ = "*" + typ
+		}
+		return typ, nil
+	case "number":
+		if s.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "array":
+		if s.Items == nil {
+			return "", errors.New("array without items")
+		}
+		elem, err := g.goType(s.Items, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	}
+
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// comment writes a doc comment, prefixed with the declared name if any
+func (g *generator) comment(indent string, name string, description string) {
+	text := strings.TrimSpace(description)
+	if name != "" && text != "" {
+		text = name + " " + text
+	}
+
+	if text == "" {
+		return
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		g.printf("%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// orderedObject decodes a JSON object, returning its keys in document order
+func orderedObject(raw json.RawMessage) ([]string, map[string]json.RawMessage, error) {
+	values := map[string]json.RawMessage{}
+	if len(raw) == 0 {
+		return nil, values, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, errors.New("expected an object")
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key := tok.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+
+		keys = append(keys, key)
+		values[key] = value
+	}
+
+	return keys, values, nil
+}
+
+// exported turns a JSON property name into an exported Go field name
+func exported(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
+
+// camel turns an UPPER_SNAKE_CASE enum value into CamelCase
+func camel(value string) string {
+	var b strings.Builder
+
+	for _, part := range strings.Split(strings.ToLower(value), "_") {
+		if part != "" {
+			b.WriteString(exported(part))
+		}
+	}
+
+	return b.String()
+}
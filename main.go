@@ -1,22 +1,37 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"os/signal"
+	"slices"
+	"syscall"
 
 	"github.com/EquiLend/1Source-Go/api"
 	"github.com/EquiLend/1Source-Go/models"
 	"github.com/EquiLend/1Source-Go/utils"
-	"github.com/Nerzal/gocloak/v13"
 )
 
 var (
 	LogFile   = "1source-go.log"
 	fileName  string
-	token     *gocloak.JWT
 	appConfig *models.AppConfig
+	client    *api.Client
+
+	// Command line switches which accept options after the entity
+	optionSwitches = []string{"-g", "-la", "-ab", "-rtp", "-rta", "-rcp", "-rrp", "-bp", "-ls", "-li", "-lb", "-ef", "-wr", "-ms", "-mq", "-lh"}
+
+	// Command line switches which change the ledger, and so can be dry run
+	mutatingSwitches = []string{"-lp", "-lb", "-ab", "-lc", "-la", "-ld", "-ls", "-li", "-rtp", "-rta", "-rtc", "-rts",
+		"-rcp", "-rcc", "-rrp", "-rra", "-rrd", "-rrc", "-bp", "-ba"}
+
+	// Command line switches which work offline, without the 1Source REST API
+	offlineSwitches = []string{"-mq"}
 )
 
 func main() {
@@ -47,6 +62,10 @@ func main() {
 
 	argsWithoutProg := os.Args[1:]
 
+	// --dry-run may be given anywhere on the command line
+	dryRun := slices.Contains(argsWithoutProg, "--dry-run")
+	argsWithoutProg = slices.DeleteFunc(slices.Clone(argsWithoutProg), func(arg string) bool { return arg == "--dry-run" })
+
 	// Command line of length 1 usually means help or version info requested
 	if len(argsWithoutProg) == 1 {
 		switch argsWithoutProg[0] {
@@ -90,8 +109,13 @@ func main() {
 		os.Exit(30)
 	}
 
-	// Command line of length 4 contains the actual command to execute
-	if len(argsWithoutProg) == 4 {
+	// Command line of length 4 or more contains the actual command to execute,
+	// followed by any options of that command
+	if len(argsWithoutProg) >= 4 {
+		// Cancel in-flight calls to the 1Source REST API on Ctrl-C or SIGTERM
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		fileName = argsWithoutProg[1]
 
 		// Read and parse configuration TOML file
@@ -102,63 +126,118 @@ func main() {
 			os.Exit(15)
 		}
 
-		// Get Auth Token using credentials from config file
-		token, err = api.GetAuthToken(appConfig)
-		var bearer string
+		// Queries of the local mirror need neither a token nor a client
+		if slices.Contains(offlineSwitches, argsWithoutProg[2]) {
+			if argsWithoutProg[2] == "-mq" {
+				queryMirror(ctx, argsWithoutProg[3], argsWithoutProg[4:])
+			}
+
+			exitIfAborted(ctx, stop)
+			return
+		}
+
+		// Get Auth Token using credentials from config file. The token
+		// source refreshes it as needed for the rest of the run
+		tokens, err := api.NewTokenSource(appConfig)
+		if err == nil {
+			_, err = tokens.Token(ctx)
+		}
 
 		if err != nil {
-			log.Panic("Error retrieving Auth Token: ", err)
-		} else {
-			bearer = `Bearer ` + token.AccessToken
+			exitIfAborted(ctx, stop)
+			log.Println("Error retrieving Auth Token: ", err)
+			fmt.Println("Error retrieving Auth Token: ", err)
+			os.Exit(35)
+		}
+
+		// Create the 1Source REST API client shared by all calls
+		client, err = api.NewClient(appConfig, tokens)
+
+		if err != nil {
+			log.Println("Error creating 1Source API client: ", err)
+			os.Exit(25)
+		}
+		defer client.CloseIdleConnections()
+
+		// In a dry run, mutating requests are printed instead of sent
+		var recorder *api.DryRun
+		if dryRun {
+			recorder = api.NewDryRun(os.Stdout)
+			client = client.WithDryRun(recorder)
 		}
 
 		// Get the 3rd and 4th command line parameters
 		// The 3rd parameter will be a switch, the 4th parameter will be the entity
+		// Any further parameters are options of the switch
 		param := argsWithoutProg[2]
 		entity := argsWithoutProg[3]
+		options := argsWithoutProg[4:]
+
+		if len(options) > 0 && !slices.Contains(optionSwitches, param) {
+			log.Println("Unknown command line flag combination")
+			os.Exit(30)
+		}
+
+		// Set by commands which can fail part way, such as a row of -lb.
+		// Those commands report whether they failed themselves, as they may
+		// rightly send nothing, such as when every row of -lb is skipped
+		var failed, reportsFailure bool
 
 		switch param {
 		// Get all of a particular type from the API
 		case "-g":
+			listOpts, err := utils.ParseListOptions(options)
+			if err != nil {
+				log.Println("Error parsing -g options: ", err)
+				fmt.Println("Error parsing -g options: ", err)
+				os.Exit(30)
+			}
+
 			switch entity {
 			case "events":
+				// Events fetched page by page have their sequence checked
+				if listOpts != nil {
+					listEvents(ctx, *listOpts)
+					break
+				}
+
 				header := "1Source Events"
-				events, err := api.GetEntity(appConfig.Endpoints.Events, bearer, header)
+				events, err := getEntities(ctx, appConfig.Endpoints.Events, header, listOpts)
 				utils.PrintResults(err, events, "Error retrieving 1Source Events: ", header)
 
 			case "parties":
 				header := "1Source Parties"
-				parties, err := api.GetEntity(appConfig.Endpoints.Parties, bearer, header)
+				parties, err := getEntities(ctx, appConfig.Endpoints.Parties, header, listOpts)
 				utils.PrintResults(err, parties, "Error retrieving 1Source Parties: ", header)
 
 			case "agreements":
 				header := "1Source Trade Agreements"
-				tas, err := api.GetEntity(appConfig.Endpoints.Agreements, bearer, header)
+				tas, err := getEntities(ctx, appConfig.Endpoints.Agreements, header, listOpts)
 				utils.PrintResults(err, tas, "Error retrieving 1Source Trade Agreements: ", header)
 
 			case "loans":
 				header := "1Source Loans"
-				loans, err := api.GetEntity(appConfig.Endpoints.Loans, bearer, header)
+				loans, err := getEntities(ctx, appConfig.Endpoints.Loans, header, listOpts)
 				utils.PrintResults(err, loans, "Error retrieving 1Source Loans: ", header)
 
 			case "rerates":
 				header := "1Source Rerates"
-				rerates, err := api.GetEntity(appConfig.Endpoints.Rerates, bearer, header)
+				rerates, err := getEntities(ctx, appConfig.Endpoints.Rerates, header, listOpts)
 				utils.PrintResults(err, rerates, "Error retrieving 1Source Rerates: ", header)
 
 			case "returns":
 				header := "1Source Returns"
-				returns, err := api.GetEntity(appConfig.Endpoints.Returns, bearer, header)
+				returns, err := getEntities(ctx, appConfig.Endpoints.Returns, header, listOpts)
 				utils.PrintResults(err, returns, "Error retrieving 1Source Returns: ", header)
 
 			case "recalls":
 				header := "1Source Recalls"
-				recalls, err := api.GetEntity(appConfig.Endpoints.Recalls, bearer, header)
+				recalls, err := getEntities(ctx, appConfig.Endpoints.Recalls, header, listOpts)
 				utils.PrintResults(err, recalls, "Error retrieving 1Source Recalls: ", header)
 
 			case "buyins":
 				header := "1Source Buyins"
-				buyins, err := api.GetEntity(appConfig.Endpoints.Buyins, bearer, header)
+				buyins, err := getEntities(ctx, appConfig.Endpoints.Buyins, header, listOpts)
 				utils.PrintResults(err, buyins, "Error retrieving 1Source Buyins: ", header)
 
 			default:
@@ -170,108 +249,273 @@ func main() {
 		case "-a":
 			header := "1Source Trade Agreement"
 			prompt := fmt.Sprintf("Error retrieving Trade Agreement with agreement_id = [%s]: ", entity)
-			agreement, err := api.GetEntityById(appConfig.Endpoints.Agreements, entity, bearer, header)
+			agreement, err := client.GetEntityById(ctx, appConfig.Endpoints.Agreements, entity, header)
 			utils.PrintResults(err, agreement, prompt, header)
 
 		// Get event agreement by event_id
 		case "-e":
 			header := "1Source Event"
 			prompt := fmt.Sprintf("Error retrieving Event with event_id = [%s]: ", entity)
-			event, err := api.GetEntityById(appConfig.Endpoints.Events, entity, bearer, header)
+			event, err := client.GetEntityById(ctx, appConfig.Endpoints.Events, entity, header)
 			utils.PrintResults(err, event, prompt, header)
 
+		// Follow new events from a checkpoint file
+		case "-ef":
+			followEvents(ctx, entity, options)
+
+		// Relay new events to webhooks from a checkpoint file
+		case "-wr":
+			relayEvents(ctx, entity, options)
+
+		// Sync the local mirror database
+		case "-ms":
+			syncMirror(ctx, entity, options)
+
 		// Get loan by loan_id
 		case "-l":
 			header := "1Source Loan"
 			prompt := fmt.Sprintf("Error retrieving Loan with loan_id = [%s]: ", entity)
-			loan, err := api.GetEntityById(appConfig.Endpoints.Loans, entity, bearer, header)
+			loan, err := client.GetEntityById(ctx, appConfig.Endpoints.Loans, entity, header)
 			utils.PrintResults(err, loan, prompt, header)
 
 		// Get loan history by loan_id
 		case "-lh":
-			header := "1Source Loan History"
-			prompt := fmt.Sprintf("Error retrieving Loan History with loan_id = [%s]: ", entity)
-			endPoint := appConfig.Endpoints.Loans + "/" + entity + "/history"
-			history, err := api.GetEntity(endPoint, bearer, header)
-			utils.PrintResults(err, history, prompt, header)
+			printLoanHistory(ctx, entity, options)
 
 		// Get party by party_id
 		case "-p":
 			header := "1Source Party"
 			prompt := fmt.Sprintf("Error retrieving 1Source with party_id = [%s]: ", entity)
-			party, err := api.GetEntityById(appConfig.Endpoints.Parties, entity, bearer, "Party")
+			party, err := client.GetEntityById(ctx, appConfig.Endpoints.Parties, entity, "Party")
 			utils.PrintResults(err, party, prompt, header)
 
 		// Propose loan
 		case "-lp":
-			// Read on JSON file specified on the command line as bytes
-			body, err := os.ReadFile(entity)
+			// Read the loan proposal from the JSON file specified on the command line
+			proposal, err := utils.ReadLoanProposal(entity)
 			if err != nil {
 				fmt.Printf("Error JSON reading file [%s]: %s\n", entity, err)
 				log.Printf("Error JSON reading file [%s]: %s\n", entity, err)
+				break
 			}
 
-			// Do HTTP PostProposeLoan to initiate the loan
-			resp, err := api.PostProposeLoan(appConfig.Endpoints.Loans, bearer, body)
+			// Do HTTP POST to initiate the loan
+			resp, err := client.ProposeLoan(ctx, *proposal)
 
 			if err == nil {
 				fmt.Println("Success: ", resp)
-			} else {
+			} else if !errors.Is(err, api.ErrDryRun) {
 				fmt.Println("Error proposing loan: ", err)
 			}
 
+		// Propose loans in bulk
+		case "-lb":
+			failed = !proposeLoans(ctx, entity, options)
+			reportsFailure = true
+
+		// Book a loan from a trade agreement
+		case "-ab":
+			fs := flag.NewFlagSet("-ab", flag.ContinueOnError)
+			var settlement utils.SettlementFlags
+			settlement.Register(fs)
+			parseOptions(fs, options)
+
+			psi, err := settlement.Instruction()
+			if err != nil {
+				fmt.Println("Error reading settlement instruction: ", err)
+				log.Println("Error reading settlement instruction: ", err)
+				break
+			}
+
+			booked, err := client.BookLoanFromAgreement(ctx, entity, *psi)
+
+			switch {
+			case errors.Is(err, api.ErrDryRun):
+			case booked == nil:
+				log.Println("Error booking loan from trade agreement: ", err)
+				fmt.Println("Error booking loan from trade agreement: ", err)
+			case booked.Loan == nil:
+				fmt.Println("Success: ", booked.Message)
+				fmt.Printf("The loan booked from trade agreement [%s] could not be found: %v\n", entity, err)
+			default:
+				fmt.Println("Success: ", booked.Message)
+				fmt.Printf("Loan with id [%s] booked from trade agreement [%s]. Loan status: %s\n", booked.Loan.LoanId, entity, booked.Loan.LoanStatus)
+			}
+
 		// Cancel a proposed loan
 		case "-lc":
-			// Get the Loan by loan_id - check that it is in the proposed state
-			loan, err := api.GetEntityById(appConfig.Endpoints.Loans, entity, bearer, "1Source Loan")
+			// Cancel the Loan by loan_id - the api checks that its state allows it
+			resp, err := client.CancelLoan(ctx, entity)
 
-			if err != nil {
-				log.Printf("Error GET %s by id [%s]: %s", "Loan", entity, err)
+			if api.IsNotFound(err) {
+				fmt.Printf("Loan with id [%s] does not exist\n", entity)
 			} else {
-				// Check the state of the loan
-				if strings.Contains(loan, "PROPOSED") {
-					// Do HTTP PostCanceLoan to cancel the loan
-					endPoint := appConfig.Endpoints.Loans + "/" + entity + "/cancel"
-					resp, err := api.PostCancelLoan(endPoint, bearer)
-
-					if err == nil {
-						fmt.Println("Successful: ", resp)
-					} else {
-						fmt.Println("Error canceling loan: ", err)
-					}
-
-				} else {
-					fmt.Printf("Loan with id [%s] is not in PROPOSED state and cannot be canceled\n", entity)
+				printOutcome(resp, err, "Error canceling loan: ")
+			}
+
+		// Approve a proposed loan
+		case "-la":
+			fs := flag.NewFlagSet("-la", flag.ContinueOnError)
+			var settlement utils.SettlementFlags
+			settlement.Register(fs)
+			roundingRule := fs.Int("rounding-rule", 0, "rounding rule of the collateral value")
+			roundingMode := fs.String("rounding-mode", "", "rounding mode of the collateral value [ALWAYSUP, ALWAYSDOWN]")
+			parseOptions(fs, options)
+
+			psi, err := settlement.Instruction()
+			if err != nil {
+				fmt.Println("Error reading settlement instruction: ", err)
+				log.Println("Error reading settlement instruction: ", err)
+				break
+			}
+
+			approval := models.LoanProposalApproval{
+				Settlement:   *psi,
+				RoundingMode: models.RoundingMode(*roundingMode),
+			}
+
+			// A rounding rule of 0 is sent only when given
+			fs.Visit(func(f *flag.Flag) {
+				if f.Name == "rounding-rule" {
+					rule := int32(*roundingRule)
+					approval.RoundingRule = &rule
 				}
+			})
+
+			// Do HTTP POST to approve the loan
+			loan, err := client.ApproveLoan(ctx, entity, approval)
+
+			if err == nil {
+				fmt.Printf("Loan with id [%s] approved. Loan status: %s, settlement status: %s\n", entity, loan.LoanStatus, loan.SettlementStatus)
+			} else if !errors.Is(err, api.ErrDryRun) {
+				log.Println("Error approving loan: ", err)
+				fmt.Println("Error approving loan: ", err)
 			}
 
 		// Decline a proposed loan
 		case "-ld":
-			// Decline the Loan by loan_id - check that it is in the proposed state
-			loan, err := api.GetEntityById(appConfig.Endpoints.Loans, entity, bearer, "1Source Loan")
+			// Decline the Loan by loan_id - the api checks that its state allows it
+			resp, err := client.DeclineLoan(ctx, entity)
 
-			if err != nil {
-				log.Printf("Error GET %s by id [%s]: %s", "Loan", entity, err)
+			if api.IsNotFound(err) {
+				fmt.Printf("Loan with id [%s] does not exist\n", entity)
 			} else {
-				// Check the state of the loan
-				if strings.Contains(loan, "PROPOSED") {
-					// Do HTTP PostCancelLoan to cancel the loan
-					endPoint := appConfig.Endpoints.Loans + "/" + entity + "/decline"
-					resp, err := api.PostDeclineLoan(endPoint, bearer)
-
-					if err == nil {
-						fmt.Println("Successful: ", resp)
-					} else {
-						fmt.Println("Error canceling loan: ", err)
-					}
-
-				} else {
-					fmt.Printf("Loan with id [%s] is not in PROPOSED state and cannot be canceled\n", entity)
-				}
+				printOutcome(resp, err, "Error declining loan: ")
 			}
 
+		// Settlement of an approved loan
+		case "-ls":
+			updateSettlementStatus(ctx, entity, options)
+
+		case "-li":
+			updateSettlementInstruction(ctx, entity, options)
+
+		// Returns lifecycle
+		case "-rtp":
+			proposeReturn(ctx, entity, options)
+
+		case "-rta":
+			acknowledgeReturn(ctx, entity, options)
+
+		case "-rtc":
+			cancelReturn(ctx, entity)
+
+		case "-rts":
+			settleReturn(ctx, entity)
+
+		// Recalls lifecycle
+		case "-rcp":
+			proposeRecall(ctx, entity, options)
+
+		case "-rcc":
+			cancelRecall(ctx, entity)
+
+		case "-rco":
+			listOpenRecalls(ctx, entity)
+
+		// Rerates lifecycle
+		case "-rrp":
+			proposeRerate(ctx, entity, options)
+
+		case "-rra":
+			approveRerate(ctx, entity)
+
+		case "-rrd":
+			declineRerate(ctx, entity)
+
+		case "-rrc":
+			cancelRerate(ctx, entity)
+
+		// Buy-ins lifecycle
+		case "-bp":
+			submitBuyin(ctx, entity, options)
+
+		case "-ba":
+			acceptBuyin(ctx, entity)
+
 		default:
 			log.Println("Unknown command-line switch entered: ", argsWithoutProg)
 		}
+
+		// Report an interrupted command instead of a partial result
+		exitIfAborted(ctx, stop)
+
+		// A dry run which never reached the 1Source REST API, or only did for
+		// part of its work, failed pre-validation
+		if recorder != nil && slices.Contains(mutatingSwitches, param) && (failed || !reportsFailure && recorder.Requests() == 0) {
+			log.Println("Dry run failed pre-validation: ", argsWithoutProg)
+			stop()
+			os.Exit(40)
+		}
+	}
+}
+
+// getEntities retrieves entity-level data from the 1Source REST API. Without
+// list options a single page is fetched as-is, otherwise as many pages as
+// needed are fetched and returned as one JSON array
+func getEntities(ctx context.Context, endPoint string, header string, opts *api.ListOptions) (string, error) {
+	if opts == nil {
+		return client.GetEntity(ctx, endPoint, header)
+	}
+
+	entities, err := client.ListEntity(ctx, endPoint, *opts)
+	if err != nil {
+		log.Printf("Error GET %s: %s", header, err)
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(entities, "", "  ")
+	if err != nil {
+		return "", err
 	}
+
+	log.Printf("%s: retrieved %d entities", header, len(entities))
+
+	return string(data), nil
+}
+
+// parseOptions parses the options of a command line switch, exiting on error
+func parseOptions(fs *flag.FlagSet, options []string) {
+	if err := fs.Parse(options); err != nil {
+		log.Printf("Error parsing %s options: %s", fs.Name(), err)
+		os.Exit(30)
+	}
+
+	if fs.NArg() > 0 {
+		log.Printf("Unexpected %s arguments: %v", fs.Name(), fs.Args())
+		fmt.Printf("Unexpected %s arguments: %v\n", fs.Name(), fs.Args())
+		os.Exit(30)
+	}
+}
+
+// exitIfAborted logs and exits when the command was canceled by Ctrl-C or SIGTERM
+func exitIfAborted(ctx context.Context, stop context.CancelFunc) {
+	if ctx.Err() == nil {
+		return
+	}
+
+	log.Println("Command aborted by signal: ", os.Args[1:])
+	fmt.Println("Aborted")
+	stop()
+	os.Exit(130)
 }
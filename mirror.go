@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/mirror"
+)
+
+// syncMirror brings the local mirror database up to date with the ledger
+func syncMirror(ctx context.Context, dbFile string, options []string) {
+	fs := flag.NewFlagSet("-ms", flag.ContinueOnError)
+	full := fs.Bool("full", false, "download every entity again instead of the events since the last sync")
+	parseOptions(fs, options)
+
+	m, err := mirror.Open(dbFile)
+	if err != nil {
+		log.Println("Error opening mirror: ", err)
+		fmt.Println("Error opening mirror: ", err)
+		return
+	}
+	defer m.Close()
+
+	stats, err := m.Sync(ctx, client, *full)
+	if err != nil {
+		log.Println("Error syncing mirror: ", err)
+		fmt.Println("Error syncing mirror: ", err)
+		return
+	}
+
+	if stats.Full {
+		fmt.Printf("Full sync of %s\n", dbFile)
+	} else {
+		fmt.Printf("Incremental sync of %s\n", dbFile)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, kind := range mirror.Kinds {
+		if kind == mirror.KindEvents {
+			fmt.Fprintf(w, "%s\t%d\n", kind, stats.Events)
+		} else {
+			fmt.Fprintf(w, "%s\t%d\n", kind, stats.Entities[kind])
+		}
+	}
+	w.Flush()
+
+	if stats.Sequence != nil {
+		printSequence(stats.Sequence)
+	}
+}
+
+// queryMirror prints the entities of a kind in the local mirror database
+// matching the filter options, without calling the 1Source REST API
+func queryMirror(ctx context.Context, kind string, options []string) {
+	fs := flag.NewFlagSet("-mq", flag.ContinueOnError)
+	dbFile := fs.String("db", mirror.DefaultFile, "mirror database file")
+	var filter mirror.Filter
+	fs.StringVar(&filter.Status, "status", "", "entity status, or event type for events")
+	fs.StringVar(&filter.Ticker, "ticker", "", "ticker of the security")
+	fs.StringVar(&filter.Counterparty, "counterparty", "", "party_id of the borrower or the lender")
+	fs.StringVar(&filter.From, "from", "", "first date, YYYY-MM-DD")
+	fs.StringVar(&filter.To, "to", "", "last date, YYYY-MM-DD")
+	fs.IntVar(&filter.Limit, "limit", 0, "maximum number of results")
+	asJSON := fs.Bool("json", false, "print the entities as a JSON array")
+	parseOptions(fs, options)
+
+	for _, date := range []string{filter.From, filter.To} {
+		if date == "" {
+			continue
+		}
+
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			fmt.Printf("Error parsing date '%s': expected YYYY-MM-DD\n", date)
+			os.Exit(30)
+		}
+	}
+
+	if _, err := os.Stat(*dbFile); err != nil {
+		log.Println("Error opening mirror: ", err)
+		fmt.Println("Error opening mirror: ", err)
+		return
+	}
+
+	m, err := mirror.Open(*dbFile)
+	if err != nil {
+		log.Println("Error opening mirror: ", err)
+		fmt.Println("Error opening mirror: ", err)
+		return
+	}
+	defer m.Close()
+
+	records, err := m.Query(ctx, kind, filter)
+	if err != nil {
+		log.Println("Error querying mirror: ", err)
+		fmt.Println("Error querying mirror: ", err)
+		return
+	}
+
+	if *asJSON {
+		data := make([]json.RawMessage, len(records))
+		for i, r := range records {
+			data[i] = r.Data
+		}
+
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			fmt.Println("Error formatting results: ", err)
+			return
+		}
+
+		fmt.Println(string(out))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columnsOf(kind), "\t"))
+	for _, r := range records {
+		row := []string{r.Id, r.Status, r.Ticker, r.Date}
+		if kind == mirror.KindEvents {
+			row = []string{r.Id, r.Status, r.Date}
+		} else if slices.Contains([]string{mirror.KindRerates, mirror.KindReturns, mirror.KindRecalls, mirror.KindBuyins}, kind) {
+			row = append(row, r.LoanId)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	fmt.Printf("%d %s\n", len(records), kind)
+}
+
+// columnsOf returns the table header of the query results of a kind
+func columnsOf(kind string) []string {
+	switch kind {
+	case mirror.KindEvents:
+		return []string{"EVENT_ID", "EVENT_TYPE", "EVENT_DATE_TIME"}
+	case mirror.KindRerates, mirror.KindReturns, mirror.KindRecalls, mirror.KindBuyins:
+		return []string{"ID", "STATUS", "TICKER", "DATE", "LOAN_ID"}
+	default:
+		return []string{"ID", "STATUS", "TICKER", "DATE"}
+	}
+}
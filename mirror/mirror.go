@@ -0,0 +1,215 @@
+// Package mirror keeps a local copy of the 1Source ledger in an embedded
+// SQLite database, so that it can be queried without calling the 1Source
+// REST API. A first sync downloads every entity; later syncs apply the
+// events since the last one
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// DefaultFile is the mirror database used when none is given
+const DefaultFile = "1source.db"
+
+// Kinds of the entities in the mirror, named as the -g queries
+const (
+	KindParties    = "parties"
+	KindAgreements = "agreements"
+	KindLoans      = "loans"
+	KindRerates    = "rerates"
+	KindReturns    = "returns"
+	KindRecalls    = "recalls"
+	KindBuyins     = "buyins"
+	KindEvents     = "events"
+)
+
+// Kinds lists the kinds of entities in the mirror
+var Kinds = []string{KindParties, KindAgreements, KindLoans, KindRerates, KindReturns, KindRecalls, KindBuyins, KindEvents}
+
+// schema creates the tables of the mirror. Every entity is kept whole as JSON
+// in data, with the fields it can be filtered by in their own columns.
+// ticker, borrower and lender are only set for agreements and loans; the
+// other entities are filtered by those of their loan
+const schema = `
+CREATE TABLE IF NOT EXISTS entities (
+	kind          TEXT NOT NULL,
+	id            TEXT NOT NULL,
+	loan_id       TEXT,
+	status        TEXT,
+	ticker        TEXT,
+	borrower      TEXT,
+	lender        TEXT,
+	date          TEXT,
+	last_event_id INTEGER,
+	data          TEXT NOT NULL,
+	PRIMARY KEY (kind, id)
+);
+CREATE INDEX IF NOT EXISTS entities_loan_id ON entities (loan_id);
+CREATE TABLE IF NOT EXISTS events (
+	event_id        INTEGER PRIMARY KEY,
+	event_type      TEXT NOT NULL,
+	event_date_time TEXT NOT NULL,
+	resource_uri    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sync_state (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// Mirror is a local copy of the 1Source ledger
+type Mirror struct {
+	db *sql.DB
+}
+
+// Open opens the mirror database, creating it if needed
+func Open(filename string) (*Mirror, error) {
+	db, err := sql.Open("sqlite", "file:"+filename+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("opening mirror '%s': %w", filename, err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating mirror '%s': %w", filename, err)
+	}
+
+	return &Mirror{db: db}, nil
+}
+
+// Close closes the mirror database
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+// entityRow holds the columns of an entity in the mirror
+type entityRow struct {
+	kind, id, loanId, status string
+	ticker, borrower, lender string
+	date                     string
+	lastEventId              uint64
+}
+
+// rowOf returns the columns of an entity model
+func rowOf(entity any) (entityRow, error) {
+	switch e := entity.(type) {
+	case *models.Party:
+		return entityRow{kind: KindParties, id: e.PartyId}, nil
+	case *models.Agreement:
+		r := tradeRow(KindAgreements, e.AgreementId, &e.Trade)
+		r.status, r.lastEventId = string(e.Status), e.LastEventId
+		return r, nil
+	case *models.Loan:
+		r := tradeRow(KindLoans, e.LoanId, &e.Trade)
+		r.loanId, r.status, r.lastEventId = e.LoanId, string(e.LoanStatus), e.LastEventId
+		return r, nil
+	case *models.Rerate:
+		return entityRow{kind: KindRerates, id: e.RerateId, loanId: e.LoanId, status: string(e.Status), date: day(e.DateCreated), lastEventId: e.LastEventId}, nil
+	case *models.Return:
+		return entityRow{kind: KindReturns, id: e.ReturnId, loanId: e.LoanId, status: string(e.Status), date: day(e.ReturnDate), lastEventId: e.LastEventId}, nil
+	case *models.Recall:
+		return entityRow{kind: KindRecalls, id: e.RecallId, loanId: e.LoanId, status: string(e.Status), date: day(e.RecallDate), lastEventId: e.LastEventId}, nil
+	case *models.Buyin:
+		return entityRow{kind: KindBuyins, id: e.BuyinId, loanId: e.LoanId, status: string(e.Status), date: day(e.DateCreated), lastEventId: e.LastEventId}, nil
+	}
+
+	return entityRow{}, fmt.Errorf("no mirror table for %T", entity)
+}
+
+// tradeRow returns the columns of an agreement or loan taken from its trade
+func tradeRow(kind string, id string, t *models.Trade) entityRow {
+	r := entityRow{kind: kind, id: id, ticker: t.Instrument.Ticker, date: day(t.TradeDate)}
+
+	for _, tp := range t.TransactingParties {
+		switch tp.PartyRole {
+		case models.PartyRoleBorrower:
+			r.borrower = tp.Party.PartyId
+		case models.PartyRoleLender:
+			r.lender = tp.Party.PartyId
+		}
+	}
+
+	return r
+}
+
+// day returns the YYYY-MM-DD date of a date or date-time
+func day(value string) string {
+	if len(value) > 10 {
+		return value[:10]
+	}
+
+	return value
+}
+
+// execer is a *sql.DB or *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// putEntity inserts or replaces an entity
+func putEntity(ctx context.Context, db execer, entity any) error {
+	r, err := rowOf(entity)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT OR REPLACE INTO entities
+		(kind, id, loan_id, status, ticker, borrower, lender, date, last_event_id, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.kind, r.id, null(r.loanId), null(r.status), null(r.ticker), null(r.borrower), null(r.lender), null(r.date), int64(r.lastEventId), string(data))
+	if err != nil {
+		return fmt.Errorf("saving %s [%s]: %w", r.kind, r.id, err)
+	}
+
+	return nil
+}
+
+// putEvent inserts an event, ignoring one already in the mirror
+func putEvent(ctx context.Context, db execer, e models.Event) error {
+	_, err := db.ExecContext(ctx, `INSERT OR IGNORE INTO events (event_id, event_type, event_date_time, resource_uri)
+		VALUES (?, ?, ?, ?)`, int64(e.EventId), string(e.EventType), e.EventDateTime, e.ResourceUri)
+	if err != nil {
+		return fmt.Errorf("saving event [%d]: %w", e.EventId, err)
+	}
+
+	return nil
+}
+
+// null maps an empty string to SQL NULL
+func null(value string) any {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+// state returns a value of the sync state, or "" if it is not set
+func (m *Mirror) state(ctx context.Context, key string) (string, error) {
+	var value string
+
+	err := m.db.QueryRowContext(ctx, `SELECT value FROM sync_state WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return value, err
+}
+
+// setState sets a value of the sync state
+func setState(ctx context.Context, db execer, key string, value string) error {
+	_, err := db.ExecContext(ctx, `INSERT OR REPLACE INTO sync_state (key, value) VALUES (?, ?)`, key, value)
+	return err
+}
@@ -0,0 +1,179 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Filter selects entities of the mirror. Empty fields do not filter
+type Filter struct {
+	Status       string
+	Ticker       string
+	Counterparty string // party_id of the borrower or the lender
+	From         string // first date, YYYY-MM-DD
+	To           string // last date, YYYY-MM-DD
+	Limit        int
+}
+
+// Record is an entity of the mirror. Date is the trade date of agreements
+// and loans, the return or recall date of returns and recalls, the creation
+// date of rerates and buy-ins and the event date of events
+type Record struct {
+	Kind   string          `json:"kind"`
+	Id     string          `json:"id"`
+	LoanId string          `json:"loanId,omitempty"`
+	Status string          `json:"status,omitempty"`
+	Ticker string          `json:"ticker,omitempty"`
+	Date   string          `json:"date,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Query returns the entities of a kind matching the filter, newest first.
+// Entities of a loan (rerates, returns, recalls and buy-ins) match the
+// ticker and counterparty of their loan
+func (m *Mirror) Query(ctx context.Context, kind string, f Filter) ([]Record, error) {
+	if !slices.Contains(Kinds, kind) {
+		return nil, fmt.Errorf("unknown kind '%s', expected one of %s", kind, strings.Join(Kinds, ", "))
+	}
+
+	if kind == KindEvents {
+		return m.queryEvents(ctx, f)
+	}
+
+	if kind == KindParties && (f.Status != "" || f.Ticker != "" || f.From != "" || f.To != "") {
+		return nil, fmt.Errorf("parties can only be filtered by counterparty")
+	}
+
+	query := `SELECT e.kind, e.id, COALESCE(e.loan_id, ''), COALESCE(e.status, ''),
+		COALESCE(e.ticker, l.ticker, ''), COALESCE(e.date, ''), e.data
+		FROM entities e
+		LEFT JOIN entities l ON l.kind = 'loans' AND l.id = e.loan_id AND e.kind != 'loans'
+		WHERE e.kind = ?`
+	args := []any{kind}
+
+	if f.Status != "" {
+		query += ` AND e.status = ?`
+		args = append(args, strings.ToUpper(f.Status))
+	}
+
+	if f.Ticker != "" {
+		query += ` AND UPPER(COALESCE(e.ticker, l.ticker)) = ?`
+		args = append(args, strings.ToUpper(f.Ticker))
+	}
+
+	if f.Counterparty != "" {
+		if kind == KindParties {
+			query += ` AND e.id = ?`
+			args = append(args, f.Counterparty)
+		} else {
+			query += ` AND ? IN (COALESCE(e.borrower, l.borrower), COALESCE(e.lender, l.lender))`
+			args = append(args, f.Counterparty)
+		}
+	}
+
+	if f.From != "" {
+		query += ` AND e.date >= ?`
+		args = append(args, f.From)
+	}
+
+	if f.To != "" {
+		query += ` AND e.date <= ?`
+		args = append(args, f.To)
+	}
+
+	query += ` ORDER BY e.date DESC, e.last_event_id DESC, e.id`
+
+	if f.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, f.Limit)
+	}
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s: %w", kind, err)
+	}
+
+	return scan(rows, func(r *Record, data *string) []any {
+		return []any{&r.Kind, &r.Id, &r.LoanId, &r.Status, &r.Ticker, &r.Date, data}
+	})
+}
+
+// queryEvents returns the events matching the filter, newest first. Status
+// filters by event type
+func (m *Mirror) queryEvents(ctx context.Context, f Filter) ([]Record, error) {
+	if f.Ticker != "" || f.Counterparty != "" {
+		return nil, fmt.Errorf("events can only be filtered by status (event type) and date")
+	}
+
+	query := `SELECT event_id, event_type, event_date_time, resource_uri FROM events WHERE 1 = 1`
+	var args []any
+
+	if f.Status != "" {
+		query += ` AND event_type = ?`
+		args = append(args, strings.ToUpper(f.Status))
+	}
+
+	if f.From != "" {
+		query += ` AND SUBSTR(event_date_time, 1, 10) >= ?`
+		args = append(args, f.From)
+	}
+
+	if f.To != "" {
+		query += ` AND SUBSTR(event_date_time, 1, 10) <= ?`
+		args = append(args, f.To)
+	}
+
+	query += ` ORDER BY event_id DESC`
+
+	if f.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, f.Limit)
+	}
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying events: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var id int64
+		var eventType, dateTime, uri string
+
+		if err := rows.Scan(&id, &eventType, &dateTime, &uri); err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(map[string]any{"eventId": id, "eventType": eventType, "eventDateTime": dateTime, "resourceUri": uri})
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, Record{Kind: KindEvents, Id: fmt.Sprint(id), Status: eventType, Date: dateTime, Data: data})
+	}
+
+	return records, rows.Err()
+}
+
+// scan reads the records of a query
+func scan(rows *sql.Rows, columns func(r *Record, data *string) []any) ([]Record, error) {
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var r Record
+		var data string
+
+		if err := rows.Scan(columns(&r, &data)...); err != nil {
+			return nil, err
+		}
+
+		r.Data = json.RawMessage(data)
+		records = append(records, r)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,183 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// testTrade returns a trade of a ticker between a lender and a borrower
+func testTrade(ticker string, lender string, borrower string, tradeDate string) models.Trade {
+	return models.Trade{
+		Instrument: models.Instrument{Ticker: ticker},
+		TradeDate:  tradeDate,
+		TransactingParties: []models.TransactingParty{
+			{PartyRole: models.PartyRoleLender, Party: models.Party{PartyId: lender}},
+			{PartyRole: models.PartyRoleBorrower, Party: models.Party{PartyId: borrower}},
+		},
+	}
+}
+
+// openQueryMirror opens a mirror holding an entity or two of each kind.
+// Loan L1 is of IBM between TLEN-US and TBORR-US, loan L2 of AAPL between
+// TLEN-US and TBORR-2, and rerate R3 is of a loan not in the mirror
+func openQueryMirror(t *testing.T) *Mirror {
+	t.Helper()
+
+	ctx := context.Background()
+	m := openTestMirror(t)
+
+	entities := []any{
+		&models.Party{PartyId: "TLEN-US"},
+		&models.Party{PartyId: "TBORR-US"},
+		&models.Agreement{AgreementId: "A1", Status: "PROPOSED", Trade: testTrade("MSFT", "TLEN-2", "TBORR-US", "2023-10-30")},
+		&models.Loan{LoanId: "L1", LoanStatus: models.LoanStatusOpen, LastEventId: 10, Trade: testTrade("IBM", "TLEN-US", "TBORR-US", "2023-11-01")},
+		&models.Loan{LoanId: "L2", LoanStatus: models.LoanStatusProposed, LastEventId: 11, Trade: testTrade("AAPL", "TLEN-US", "TBORR-2", "2023-11-05")},
+		&models.Rerate{RerateId: "R1", LoanId: "L1", Status: models.RerateStatusProposed, DateCreated: "2023-11-02T10:00:00Z"},
+		&models.Rerate{RerateId: "R2", LoanId: "L2", Status: models.RerateStatusApproved, DateCreated: "2023-11-06T10:00:00Z"},
+		&models.Rerate{RerateId: "R3", LoanId: "L9", Status: models.RerateStatusProposed, DateCreated: "2023-11-07T10:00:00Z"},
+		&models.Return{ReturnId: "T1", LoanId: "L1", Status: "PENDING", ReturnDate: "2023-11-03"},
+		&models.Recall{RecallId: "C1", LoanId: "L2", Status: "OPEN", RecallDate: "2023-11-08"},
+		&models.Buyin{BuyinId: "B1", LoanId: "L1", Status: "PROPOSED", DateCreated: "2023-11-09T08:00:00Z"},
+	}
+	for _, e := range entities {
+		if err := putEntity(ctx, m.db, e); err != nil {
+			t.Fatalf("putEntity: %v", err)
+		}
+	}
+
+	evts := models.Events{
+		{EventId: 1, EventType: models.EventTypeTrade, EventDateTime: "2023-10-30T09:00:00Z", ResourceUri: "/agreements/A1"},
+		{EventId: 2, EventType: models.EventTypeContractProposed, EventDateTime: "2023-11-01T09:00:00Z", ResourceUri: "/loans/L1"},
+		{EventId: 3, EventType: models.EventTypeContractProposed, EventDateTime: "2023-11-05T09:00:00Z", ResourceUri: "/loans/L2"},
+		{EventId: 4, EventType: models.EventTypeRerateProposed, EventDateTime: "2023-11-05T23:59:59Z", ResourceUri: "/rerates/R1"},
+	}
+	for _, e := range append(evts, evts[0]) {
+		if err := putEvent(ctx, m.db, e); err != nil {
+			t.Fatalf("putEvent: %v", err)
+		}
+	}
+
+	return m
+}
+
+func TestQuery(t *testing.T) {
+	m := openQueryMirror(t)
+
+	tests := []struct {
+		kind   string
+		filter Filter
+		want   []string
+	}{
+		{KindLoans, Filter{}, []string{"L2", "L1"}},
+		{KindLoans, Filter{Status: "open"}, []string{"L1"}},
+		{KindLoans, Filter{Ticker: "ibm"}, []string{"L1"}},
+		{KindLoans, Filter{Counterparty: "TBORR-2"}, []string{"L2"}},
+		{KindLoans, Filter{Counterparty: "TLEN-US"}, []string{"L2", "L1"}},
+		{KindLoans, Filter{Counterparty: "TLEN-2"}, nil},
+		{KindLoans, Filter{From: "2023-11-02"}, []string{"L2"}},
+		{KindLoans, Filter{To: "2023-11-01"}, []string{"L1"}},
+		{KindLoans, Filter{From: "2023-11-01", To: "2023-11-01"}, []string{"L1"}},
+		{KindLoans, Filter{Limit: 1}, []string{"L2"}},
+		{KindAgreements, Filter{Ticker: "MSFT", Counterparty: "TBORR-US"}, []string{"A1"}},
+		{KindAgreements, Filter{Counterparty: "TLEN-US"}, nil},
+
+		// Entities of a loan match the ticker and counterparty of their loan
+		{KindRerates, Filter{}, []string{"R3", "R2", "R1"}},
+		{KindRerates, Filter{Ticker: "IBM"}, []string{"R1"}},
+		{KindRerates, Filter{Counterparty: "TBORR-2"}, []string{"R2"}},
+		{KindRerates, Filter{Counterparty: "TLEN-US", Status: "PROPOSED"}, []string{"R1"}},
+		{KindRerates, Filter{From: "2023-11-02", To: "2023-11-06"}, []string{"R2", "R1"}},
+		{KindReturns, Filter{Counterparty: "TBORR-US", Ticker: "IBM"}, []string{"T1"}},
+		{KindReturns, Filter{Ticker: "AAPL"}, nil},
+		{KindRecalls, Filter{Ticker: "aapl", From: "2023-11-08"}, []string{"C1"}},
+		{KindBuyins, Filter{Ticker: "IBM", From: "2023-11-09", To: "2023-11-09"}, []string{"B1"}},
+		{KindBuyins, Filter{To: "2023-11-08"}, nil},
+
+		{KindParties, Filter{}, []string{"TBORR-US", "TLEN-US"}},
+		{KindParties, Filter{Counterparty: "TLEN-US"}, []string{"TLEN-US"}},
+
+		// Events are newest first, filtered by type and the day of their time
+		{KindEvents, Filter{}, []string{"4", "3", "2", "1"}},
+		{KindEvents, Filter{Status: "contract_proposed"}, []string{"3", "2"}},
+		{KindEvents, Filter{From: "2023-11-01", To: "2023-11-05"}, []string{"4", "3", "2"}},
+		{KindEvents, Filter{To: "2023-10-31"}, []string{"1"}},
+		{KindEvents, Filter{Limit: 2}, []string{"4", "3"}},
+	}
+
+	for _, tt := range tests {
+		records, err := m.Query(context.Background(), tt.kind, tt.filter)
+		if err != nil {
+			t.Errorf("Query(%s, %+v) = %v", tt.kind, tt.filter, err)
+			continue
+		}
+
+		if got := ids(records); !slices.Equal(got, tt.want) {
+			t.Errorf("Query(%s, %+v) = %v, want %v", tt.kind, tt.filter, got, tt.want)
+		}
+
+		for _, r := range records {
+			if r.Kind != tt.kind || !json.Valid(r.Data) {
+				t.Errorf("Query(%s) record %+v", tt.kind, r)
+			}
+		}
+	}
+}
+
+func TestQueryRecord(t *testing.T) {
+	m := openQueryMirror(t)
+	ctx := context.Background()
+
+	records, err := m.Query(ctx, KindRerates, Filter{Ticker: "IBM"})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Query = %+v, %v", records, err)
+	}
+
+	// The rerate has no ticker of its own and shows that of its loan
+	r := records[0]
+	if r.LoanId != "L1" || r.Status != "PROPOSED" || r.Ticker != "IBM" || r.Date != "2023-11-02" {
+		t.Errorf("record = %+v", r)
+	}
+
+	var rerate models.Rerate
+	if err := json.Unmarshal(r.Data, &rerate); err != nil || rerate.RerateId != "R1" || rerate.DateCreated != "2023-11-02T10:00:00Z" {
+		t.Errorf("record data = %s (%v)", r.Data, err)
+	}
+
+	records, err = m.Query(ctx, KindEvents, Filter{Status: "RERATE_PROPOSED"})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Query events = %+v, %v", records, err)
+	}
+
+	var e models.Event
+	if err := json.Unmarshal(records[0].Data, &e); err != nil || e.EventId != 4 || e.ResourceUri != "/rerates/R1" || records[0].Date != e.EventDateTime {
+		t.Errorf("event record = %+v (%v)", records[0], err)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	m := openQueryMirror(t)
+
+	tests := []struct {
+		kind   string
+		filter Filter
+		want   string
+	}{
+		{"trades", Filter{}, "unknown kind 'trades'"},
+		{KindParties, Filter{Status: "OPEN"}, "parties can only be filtered by counterparty"},
+		{KindParties, Filter{From: "2023-11-01"}, "parties can only be filtered by counterparty"},
+		{KindEvents, Filter{Ticker: "IBM"}, "events can only be filtered by status"},
+		{KindEvents, Filter{Counterparty: "TLEN-US"}, "events can only be filtered by status"},
+	}
+
+	for _, tt := range tests {
+		_, err := m.Query(context.Background(), tt.kind, tt.filter)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Query(%s, %+v) = %v, want an error containing %q", tt.kind, tt.filter, err, tt.want)
+		}
+	}
+}
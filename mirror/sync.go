@@ -0,0 +1,266 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/events"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// Keys of the sync state
+const (
+	stateLastEventId       = "last_event_id"
+	stateLastEventDateTime = "last_event_date_time"
+	stateLastSync          = "last_sync"
+)
+
+// SyncStats counts what a sync saved in the mirror
+type SyncStats struct {
+	Full     bool
+	Entities map[string]int
+	Events   int
+	Sequence *events.SequenceReport
+}
+
+// Sync brings the mirror up to date. The first sync, or one with full set,
+// downloads every entity and event. Later syncs apply the events after the
+// last one seen: the entity each event refers to is fetched and saved along
+// with the event, in one transaction, so an interrupted sync resumes from
+// the last event applied. Parties are not evented and are fetched each time
+func (m *Mirror) Sync(ctx context.Context, client *api.Client, full bool) (*SyncStats, error) {
+	cp, err := m.Checkpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SyncStats{Full: full || cp.IsZero(), Entities: map[string]int{}}
+
+	if stats.Full {
+		err = m.syncAll(ctx, client, stats)
+	} else {
+		err = m.syncEvents(ctx, client, cp, stats)
+	}
+
+	if err != nil {
+		return stats, err
+	}
+
+	if err := setState(ctx, m.db, stateLastSync, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// Checkpoint returns the last event applied to the mirror
+func (m *Mirror) Checkpoint(ctx context.Context) (*events.Checkpoint, error) {
+	cp := &events.Checkpoint{}
+
+	id, err := m.state(ctx, stateLastEventId)
+	if err != nil {
+		return nil, fmt.Errorf("reading mirror state: %w", err)
+	}
+
+	if id != "" {
+		if cp.LastEventId, err = strconv.ParseUint(id, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid %s '%s' in mirror state", stateLastEventId, id)
+		}
+	}
+
+	if cp.LastEventDateTime, err = m.state(ctx, stateLastEventDateTime); err != nil {
+		return nil, fmt.Errorf("reading mirror state: %w", err)
+	}
+
+	return cp, nil
+}
+
+// syncAll downloads every entity and event
+func (m *Mirror) syncAll(ctx context.Context, client *api.Client, stats *SyncStats) error {
+	// Events are listed first, so that the entities are at least as recent
+	// as the last event, which later syncs start from
+	evts, sequence, err := events.List(ctx, client, api.ListOptions{}, 0)
+	if err != nil {
+		return fmt.Errorf("listing events: %w", err)
+	}
+	stats.Sequence = sequence
+
+	if err := m.syncParties(ctx, client, stats); err != nil {
+		return err
+	}
+
+	lists := []struct {
+		kind string
+		list func() ([]any, error)
+	}{
+		{KindAgreements, func() ([]any, error) { return listAll(client.ListAgreements(ctx, api.ListOptions{})) }},
+		{KindLoans, func() ([]any, error) { return listAll(client.ListLoans(ctx, api.ListOptions{})) }},
+		{KindRerates, func() ([]any, error) { return listAll(client.ListRerates(ctx, api.ListOptions{})) }},
+		{KindReturns, func() ([]any, error) { return listAll(client.ListReturns(ctx, api.ListOptions{})) }},
+		{KindRecalls, func() ([]any, error) { return listAll(client.ListRecalls(ctx, api.ListOptions{})) }},
+		{KindBuyins, func() ([]any, error) { return listAll(client.ListBuyins(ctx, api.ListOptions{})) }},
+	}
+
+	for _, l := range lists {
+		entities, err := l.list()
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", l.kind, err)
+		}
+
+		if err := m.save(ctx, entities, nil); err != nil {
+			return err
+		}
+		stats.Entities[l.kind] += len(entities)
+	}
+
+	var last *models.Event
+	if len(evts) > 0 {
+		last = &evts[len(evts)-1]
+	}
+
+	err = m.tx(ctx, func(tx *sql.Tx) error {
+		for _, e := range evts {
+			if err := putEvent(ctx, tx, e); err != nil {
+				return err
+			}
+		}
+
+		if last == nil {
+			return nil
+		}
+
+		return advance(ctx, tx, *last)
+	})
+	if err != nil {
+		return err
+	}
+
+	stats.Events = len(evts)
+
+	return nil
+}
+
+// syncEvents applies the events after the checkpoint, one at a time. An
+// event whose entity no longer exists, or whose type has no known entity
+// model, is saved without its entity so that the checkpoint moves past it
+func (m *Mirror) syncEvents(ctx context.Context, client *api.Client, cp *events.Checkpoint, stats *SyncStats) error {
+	if err := m.syncParties(ctx, client, stats); err != nil {
+		return err
+	}
+
+	evts, sequence, err := events.After(ctx, client, cp, time.Time{}, events.DefaultLookback)
+	if err != nil {
+		return fmt.Errorf("listing events: %w", err)
+	}
+	stats.Sequence = sequence
+
+	for _, e := range evts {
+		entity, err := events.Resolve(ctx, client, e)
+		if api.IsNotFound(err) {
+			log.Printf("Entity %s of event [%d] no longer exists", e.ResourceUri, e.EventId)
+			entity, err = nil, nil
+		}
+		if errors.Is(err, events.ErrUnknownEventType) {
+			log.Printf("Saving event [%d] without its entity: %s", e.EventId, err)
+			entity, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var entities []any
+		if entity != nil {
+			entities = append(entities, entity)
+			r, _ := rowOf(entity)
+			stats.Entities[r.kind]++
+		}
+
+		if err := m.save(ctx, entities, &e); err != nil {
+			return err
+		}
+		stats.Events++
+	}
+
+	return nil
+}
+
+// syncParties downloads every party
+func (m *Mirror) syncParties(ctx context.Context, client *api.Client, stats *SyncStats) error {
+	parties, err := listAll(client.ListParties(ctx, api.ListOptions{}))
+	if err != nil {
+		return fmt.Errorf("listing parties: %w", err)
+	}
+
+	if err := m.save(ctx, parties, nil); err != nil {
+		return err
+	}
+	stats.Entities[KindParties] += len(parties)
+
+	return nil
+}
+
+// save saves entities and, if not nil, the event they were fetched for in
+// one transaction, advancing the checkpoint to the event
+func (m *Mirror) save(ctx context.Context, entities []any, event *models.Event) error {
+	return m.tx(ctx, func(tx *sql.Tx) error {
+		for _, entity := range entities {
+			if err := putEntity(ctx, tx, entity); err != nil {
+				return err
+			}
+		}
+
+		if event == nil {
+			return nil
+		}
+
+		if err := putEvent(ctx, tx, *event); err != nil {
+			return err
+		}
+
+		return advance(ctx, tx, *event)
+	})
+}
+
+// advance moves the checkpoint of the mirror to an event
+func advance(ctx context.Context, tx *sql.Tx, e models.Event) error {
+	if err := setState(ctx, tx, stateLastEventId, strconv.FormatUint(e.EventId, 10)); err != nil {
+		return err
+	}
+
+	return setState(ctx, tx, stateLastEventDateTime, e.EventDateTime)
+}
+
+// tx runs fn in a transaction, committed if fn succeeds
+func (m *Mirror) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// listAll turns a list of entity models into a list of pointers to them
+func listAll[T any](list []T, err error) ([]any, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]any, len(list))
+	for i := range list {
+		entities[i] = &list[i]
+	}
+
+	return entities, nil
+}
@@ -0,0 +1,166 @@
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/EquiLend/1Source-Go/internal/ledgertest"
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// openTestMirror opens a mirror in a temporary file, closed when the test ends
+func openTestMirror(t *testing.T) *Mirror {
+	t.Helper()
+
+	m, err := Open(filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	return m
+}
+
+// event returns an event of a type on the resource at a path of the ledger
+func event(id uint64, eventType models.EventType, path string) models.Event {
+	return models.Event{
+		EventId:       id,
+		EventType:     eventType,
+		EventDateTime: "2023-11-02T13:00:00Z",
+		ResourceUri:   ledgertest.Base + path,
+	}
+}
+
+// ids returns the ids of records
+func ids(records []Record) []string {
+	var ids []string
+	for _, r := range records {
+		ids = append(ids, r.Id)
+	}
+
+	return ids
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	ledger := ledgertest.New(t)
+	client := ledger.Client(t)
+	m := openTestMirror(t)
+
+	loan := models.Loan{LoanId: "L1", LoanStatus: models.LoanStatusProposed, LastEventId: 2}
+	evts := models.Events{
+		event(1, models.EventTypeTrade, "agreements/A1"),
+		event(2, models.EventTypeContractProposed, "loans/L1"),
+	}
+
+	ledger.Set(ledgertest.Base+"events", evts)
+	ledger.Set(ledgertest.Base+"parties", models.Parties{{PartyId: "TLEN-US"}, {PartyId: "TBORR-US"}})
+	ledger.Set(ledgertest.Base+"agreements", models.Agreements{{AgreementId: "A1", LastEventId: 1}})
+	ledger.Set(ledgertest.Base+"loans", models.Loans{loan})
+	ledger.Set(ledgertest.Base+"rerates", models.Rerates{})
+	ledger.Set(ledgertest.Base+"returns", models.Returns{})
+	ledger.Set(ledgertest.Base+"recalls", models.Recalls{})
+	ledger.Set(ledgertest.Base+"buyins", models.Buyins{})
+
+	// The first sync downloads everything
+	stats, err := m.Sync(ctx, client, false)
+	if err != nil {
+		t.Fatalf("first Sync: %v", err)
+	}
+
+	if !stats.Full || stats.Events != 2 || stats.Entities[KindParties] != 2 || stats.Entities[KindAgreements] != 1 || stats.Entities[KindLoans] != 1 {
+		t.Errorf("first Sync stats = %+v", stats)
+	}
+
+	if cp, _ := m.Checkpoint(ctx); cp.LastEventId != 2 || cp.LastEventDateTime != evts[1].EventDateTime {
+		t.Errorf("checkpoint after the first sync = %+v, want event 2", cp)
+	}
+
+	// Later syncs apply the events after the checkpoint. The entity of an
+	// unknown event type is not known, and the return has been deleted
+	loan.LoanStatus = models.LoanStatusPending
+	loan.LastEventId = 3
+	evts = append(evts,
+		event(3, models.EventTypeContractPending, "loans/L1"),
+		event(4, "COLLATERAL_MARKED", "collateral/C1"),
+		event(5, models.EventTypeReturnPending, "returns/T1"),
+		event(6, models.EventTypeRerateProposed, "rerates/R1"),
+	)
+	ledger.Set(ledgertest.Base+"events", evts)
+	ledger.Set(ledgertest.Base+"loans/L1", loan)
+	ledger.Set(ledgertest.Base+"rerates/R1", models.Rerate{RerateId: "R1", LoanId: "L1", Status: models.RerateStatusProposed, LastEventId: 6})
+
+	stats, err = m.Sync(ctx, client, false)
+	if err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+
+	if stats.Full || stats.Events != 4 || stats.Entities[KindLoans] != 1 || stats.Entities[KindRerates] != 1 || stats.Entities[KindReturns] != 0 {
+		t.Errorf("second Sync stats = %+v", stats)
+	}
+
+	if cp, _ := m.Checkpoint(ctx); cp.LastEventId != 6 {
+		t.Errorf("checkpoint after the second sync = %+v, want event 6", cp)
+	}
+
+	if records, _ := m.Query(ctx, KindLoans, Filter{}); len(records) != 1 || records[0].Status != string(models.LoanStatusPending) {
+		t.Errorf("loans after the second sync = %+v, want L1 PENDING", records)
+	}
+
+	if records, _ := m.Query(ctx, KindEvents, Filter{Status: "collateral_marked"}); len(records) != 1 || records[0].Id != "4" {
+		t.Errorf("unknown events = %v, want event 4 saved", ids(records))
+	}
+
+	// A sync without new events keeps the checkpoint
+	stats, err = m.Sync(ctx, client, false)
+	if err != nil {
+		t.Fatalf("third Sync: %v", err)
+	}
+
+	if stats.Events != 0 {
+		t.Errorf("third Sync applied %d events, want none", stats.Events)
+	}
+
+	if cp, _ := m.Checkpoint(ctx); cp.LastEventId != 6 {
+		t.Errorf("checkpoint after the third sync = %+v, want event 6", cp)
+	}
+
+	// A full sync downloads everything again
+	if stats, err = m.Sync(ctx, client, true); err != nil || !stats.Full || stats.Events != 6 {
+		t.Errorf("full Sync = %+v, %v, want every event", stats, err)
+	}
+}
+
+func TestSyncStopsOnError(t *testing.T) {
+	ctx := context.Background()
+	ledger := ledgertest.New(t)
+	client := ledger.Client(t)
+	m := openTestMirror(t)
+
+	// A checkpoint in the mirror makes the sync apply events
+	if err := m.save(ctx, nil, &models.Event{EventId: 1, EventType: models.EventTypeTrade, EventDateTime: "2023-11-01T00:00:00Z"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	ledger.Set(ledgertest.Base+"parties", models.Parties{})
+	ledger.Set(ledgertest.Base+"events", models.Events{
+		event(2, models.EventTypeContractProposed, "loans/L2"),
+		event(3, models.EventTypeContractProposed, "loans/L3"),
+	})
+	ledger.Set(ledgertest.Base+"loans/L2", models.Loan{LoanId: "L2"})
+
+	// The loan of event 3 cannot be fetched, so the sync stops after event 2
+	ledger.Handle(ledgertest.Base+"loans/L3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if _, err := m.Sync(ctx, client, false); err == nil {
+		t.Fatalf("Sync succeeded, want the error of loan L3")
+	}
+
+	if cp, _ := m.Checkpoint(ctx); cp.LastEventId != 2 {
+		t.Errorf("checkpoint = %+v, want event 2", cp)
+	}
+}
@@ -0,0 +1,21 @@
+// Package models contains the models for the application
+package models
+
+import "slices"
+
+// buyinTransitions lists the statuses a buy-in can move to from each status.
+// A buy-in is submitted by the lender as PROPOSED, then accepted by the
+// borrower or canceled; ACCEPTED and CANCELED are final
+var buyinTransitions = map[BuyinStatus][]BuyinStatus{
+	BuyinStatusProposed: {BuyinStatusAccepted, BuyinStatusCanceled},
+}
+
+// CanTransition reports whether a buy-in in status s can move to next
+func (s BuyinStatus) CanTransition(next BuyinStatus) bool {
+	return slices.Contains(buyinTransitions[s], next)
+}
+
+// IsFinal reports whether no further change is possible from status s
+func (s BuyinStatus) IsFinal() bool {
+	return len(buyinTransitions[s]) == 0
+}
@@ -6,11 +6,15 @@ type (
 		General        general
 		Endpoints      endpoints
 		Authentication authentication
+		Connection     connection
+		Retry          retry
+		Webhooks       webhooks
 	}
 
 	general struct {
 		Auth_URL   string
 		Realm_Name string
+		Party_Id   string
 	}
 
 	endpoints struct {
@@ -32,5 +36,35 @@ type (
 		Username      string
 		Password      string
 		Client_Secret string
+		Refresh_Token string
+	}
+
+	// connection holds the HTTP connection pool settings. Timeouts are
+	// in seconds and a zero value selects the built-in default
+	connection struct {
+		Timeout                 uint32
+		Dial_Timeout            uint32
+		TLS_Handshake_Timeout   uint32
+		Idle_Conn_Timeout       uint32
+		Max_Idle_Conns          int
+		Max_Idle_Conns_Per_Host int
+	}
+
+	// retry holds the retry policy for calls to the 1Source REST API.
+	// Delays are in milliseconds and a zero value selects the default
+	retry struct {
+		Max_Attempts  int
+		Base_Delay_Ms uint32
+		Max_Delay_Ms  uint32
+		Status_Codes  []int
+	}
+
+	// webhooks holds the receivers of the events relayed by the webhook
+	// relay. Each delivery is signed with the secret
+	webhooks struct {
+		Urls             []string
+		Secret           string
+		Include_Entity   bool
+		Delivery_Log_Dir string
 	}
 )
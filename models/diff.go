@@ -0,0 +1,102 @@
+// Package models contains the models for the application
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// FieldChange is the change of one field between two versions of a model.
+// Field is the JSON path of the field, such as settlement[0].instruction.settlementBic,
+// and an empty Before or After means the field was absent
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Diff compares two versions of a model field by field, through their JSON
+// encoding, and returns the fields which differ sorted by path
+func Diff(before any, after any) ([]FieldChange, error) {
+	b, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for field := range b {
+		fields = append(fields, field)
+	}
+	for field := range a {
+		if _, ok := b[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+
+	slices.Sort(fields)
+
+	var changes []FieldChange
+	for _, field := range fields {
+		if b[field] != a[field] {
+			changes = append(changes, FieldChange{Field: field, Before: b[field], After: a[field]})
+		}
+	}
+
+	return changes, nil
+}
+
+// flatten encodes a model as JSON and returns its leaf values by JSON path
+func flatten(v any) (map[string]string, error) {
+	fields := map[string]string{}
+
+	if v == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %T: %w", v, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("decoding %T: %w", v, err)
+	}
+
+	flattenInto(fields, "", tree)
+
+	return fields, nil
+}
+
+// flattenInto adds the leaf values of a decoded JSON value under path
+func flattenInto(fields map[string]string, path string, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if path != "" {
+				key = path + "." + key
+			}
+			flattenInto(fields, key, value)
+		}
+	case []any:
+		for i, value := range v {
+			flattenInto(fields, fmt.Sprintf("%s[%d]", path, i), value)
+		}
+	case nil:
+		// A null field is the same as an absent one
+	case string:
+		fields[path] = v
+	default:
+		fields[path] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   []FieldChange
+	}{
+		{"same", map[string]any{"a": 1}, map[string]any{"a": 1}, nil},
+		{"nil before", nil, map[string]any{"a": "x", "b": 2}, []FieldChange{{"a", "", "x"}, {"b", "", "2"}}},
+		{"nil after", map[string]any{"a": true}, nil, []FieldChange{{"a", "true", ""}}},
+		{"nested", map[string]any{"a": map[string]any{"b": "x"}}, map[string]any{"a": map[string]any{"b": "y"}}, []FieldChange{{"a.b", "x", "y"}}},
+		{"null is absent", map[string]any{"a": nil}, map[string]any{}, nil},
+		{"numbers kept exact", map[string]any{"q": int64(9007199254740993)}, map[string]any{"q": int64(9007199254740992)},
+			[]FieldChange{{"q", "9007199254740993", "9007199254740992"}}},
+		{"array index paths",
+			map[string]any{"s": []any{map[string]any{"bic": "A"}, map[string]any{"bic": "B"}}},
+			map[string]any{"s": []any{map[string]any{"bic": "A"}, map[string]any{"bic": "C"}, map[string]any{"bic": "D"}}},
+			[]FieldChange{{"s[1].bic", "B", "C"}, {"s[2].bic", "", "D"}}},
+		{"array shrinks", map[string]any{"s": []any{1, 2}}, map[string]any{"s": []any{1}}, []FieldChange{{"s[1]", "2", ""}}},
+		{"top level array", []any{"a", "b"}, []any{"a", "c"}, []FieldChange{{"[1]", "b", "c"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Diff =\n%+v, want\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffError(t *testing.T) {
+	if _, err := Diff(map[string]any{"c": make(chan int)}, nil); err == nil {
+		t.Errorf("Diff of an unencodable value succeeded")
+	}
+}
@@ -0,0 +1,77 @@
+// Package models contains the models for the application
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// LoanVersion is one version of a loan in its history, with the event and
+// party which made it and the fields it changed from the version before.
+// The fields of the first version are changes from absent
+type LoanVersion struct {
+	EventId        uint64        `json:"eventId"`
+	UpdatePartyId  string        `json:"updatePartyId,omitempty"`
+	UpdateDateTime string        `json:"updateDateTime,omitempty"`
+	LoanStatus     LoanStatus    `json:"loanStatus"`
+	Changes        []FieldChange `json:"changes"`
+}
+
+// versionFields describe a version of a loan rather than the loan, and are
+// left out of its changes
+var versionFields = []string{"lastEventId", "lastUpdatePartyId", "lastUpdateDateTime"}
+
+// Versions walks the versions of a loan's history, oldest first by
+// LastEventId, and returns the fields each one changed
+func (history Loans) Versions() ([]LoanVersion, error) {
+	loans := slices.Clone(history)
+	slices.SortStableFunc(loans, func(a, b Loan) int {
+		switch {
+		case a.LastEventId < b.LastEventId:
+			return -1
+		case a.LastEventId > b.LastEventId:
+			return 1
+		}
+		return strings.Compare(a.LastUpdateDateTime, b.LastUpdateDateTime)
+	})
+
+	versions := make([]LoanVersion, 0, len(loans))
+	var before *Loan
+
+	for i := range loans {
+		after := &loans[i]
+
+		// A nil before encodes as null, so every field of the first
+		// version is a change
+		changes, err := LoanChanges(before, after)
+		if err != nil {
+			return nil, err
+		}
+
+		if changes == nil {
+			changes = []FieldChange{}
+		}
+
+		versions = append(versions, LoanVersion{
+			EventId:        after.LastEventId,
+			UpdatePartyId:  after.LastUpdatePartyId,
+			UpdateDateTime: after.LastUpdateDateTime,
+			LoanStatus:     after.LoanStatus,
+			Changes:        changes,
+		})
+		before = after
+	}
+
+	return versions, nil
+}
+
+// LoanChanges returns the fields of a loan which differ between two of its
+// versions, leaving out the fields which describe the version
+func LoanChanges(before *Loan, after *Loan) ([]FieldChange, error) {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(changes, func(c FieldChange) bool { return slices.Contains(versionFields, c.Field) }), nil
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+// testLoanHistory returns three versions of a loan, out of order
+func testLoanHistory() Loans {
+	proposed := Loan{
+		LoanId:             "L1",
+		LastEventId:        10,
+		LoanStatus:         LoanStatusProposed,
+		LastUpdatePartyId:  "TLEN-US",
+		LastUpdateDateTime: "2023-11-01T10:00:00Z",
+		Trade:              Trade{Quantity: 100, TradeDate: "2023-11-01"},
+	}
+
+	approved := proposed
+	approved.LastEventId = 11
+	approved.LoanStatus = LoanStatusPending
+	approved.LastUpdatePartyId = "TBORR-US"
+	approved.LastUpdateDateTime = "2023-11-01T11:00:00Z"
+	approved.Settlement = []PartySettlementInstruction{
+		{PartyRole: PartyRoleBorrower, Instruction: SettlementInstruction{SettlementBic: "DTCYUS33"}},
+	}
+
+	settled := approved
+	settled.LastEventId = 12
+	settled.LoanStatus = LoanStatusOpen
+	settled.LastUpdateDateTime = "2023-11-02T09:00:00Z"
+
+	return Loans{settled, proposed, approved}
+}
+
+func TestLoansVersions(t *testing.T) {
+	versions, err := testLoanHistory().Versions()
+	if err != nil {
+		t.Fatalf("Versions: %v", err)
+	}
+
+	if len(versions) != 3 {
+		t.Fatalf("got %d versions, want 3", len(versions))
+	}
+
+	for i, want := range []struct {
+		eventId uint64
+		party   string
+		status  LoanStatus
+	}{
+		{10, "TLEN-US", LoanStatusProposed},
+		{11, "TBORR-US", LoanStatusPending},
+		{12, "TBORR-US", LoanStatusOpen},
+	} {
+		v := versions[i]
+		if v.EventId != want.eventId || v.UpdatePartyId != want.party || v.LoanStatus != want.status {
+			t.Errorf("version %d = %d by %s in %s, want %d by %s in %s", i, v.EventId, v.UpdatePartyId, v.LoanStatus, want.eventId, want.party, want.status)
+		}
+
+		for _, c := range v.Changes {
+			if slices.Contains(versionFields, c.Field) {
+				t.Errorf("version %d changes the version field %s", i, c.Field)
+			}
+		}
+	}
+
+	// The first version changes every field from absent
+	first := map[string]FieldChange{}
+	for _, c := range versions[0].Changes {
+		first[c.Field] = c
+	}
+	for field, after := range map[string]string{"loanId": "L1", "loanStatus": "PROPOSED", "trade.quantity": "100", "trade.tradeDate": "2023-11-01"} {
+		if c, ok := first[field]; !ok || c.Before != "" || c.After != after {
+			t.Errorf("first version change of %s = %+v, want from absent to %s", field, c, after)
+		}
+	}
+
+	want := []FieldChange{
+		{"loanStatus", "PROPOSED", "PENDING"},
+		{"settlement[0].instruction.settlementBic", "", "DTCYUS33"},
+		{"settlement[0].partyRole", "", "BORROWER"},
+	}
+	if got := versions[1].Changes; !slices.Equal(got, want) {
+		t.Errorf("second version changes =\n%+v, want\n%+v", got, want)
+	}
+
+	if got := versions[2].Changes; !slices.Equal(got, []FieldChange{{"loanStatus", "PENDING", "OPEN"}}) {
+		t.Errorf("third version changes = %+v", got)
+	}
+}
+
+func TestLoansVersionsEmpty(t *testing.T) {
+	versions, err := Loans{}.Versions()
+	if err != nil || len(versions) != 0 {
+		t.Errorf("Versions of no loans = %+v, %v", versions, err)
+	}
+
+	history := testLoanHistory()[:1]
+	history[0].LastUpdateDateTime = ""
+
+	versions, err = append(history, history[0]).Versions()
+	if err != nil || len(versions) != 2 || versions[1].Changes == nil || len(versions[1].Changes) != 0 {
+		t.Errorf("Versions of an unchanged loan = %+v, %v, want an empty change list", versions, err)
+	}
+}
+
+func TestLoanChanges(t *testing.T) {
+	history := testLoanHistory()
+	before, after := &history[1], &history[2]
+
+	changes, err := LoanChanges(before, after)
+	if err != nil {
+		t.Fatalf("LoanChanges: %v", err)
+	}
+
+	diff, _ := Diff(before, after)
+	for _, field := range versionFields {
+		if !slices.ContainsFunc(diff, func(c FieldChange) bool { return c.Field == field }) {
+			t.Errorf("Diff does not change %s, the test versions should differ in it", field)
+		}
+		if slices.ContainsFunc(changes, func(c FieldChange) bool { return c.Field == field }) {
+			t.Errorf("LoanChanges includes the version field %s", field)
+		}
+	}
+
+	if len(changes) != len(diff)-len(versionFields) {
+		t.Errorf("LoanChanges = %+v, want the Diff without the version fields", changes)
+	}
+}
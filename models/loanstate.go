@@ -0,0 +1,134 @@
+// Package models contains the models for the application
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// LoanAction is a lifecycle action on a loan, or on one of its rerates,
+// returns, recalls or buy-ins
+type LoanAction string
+
+// LoanAction values
+const (
+	LoanActionApprove LoanAction = "approve"
+	LoanActionCancel  LoanAction = "cancel"
+	LoanActionDecline LoanAction = "decline"
+	LoanActionRerate  LoanAction = "rerate"
+	LoanActionReturn  LoanAction = "return"
+	LoanActionRecall  LoanAction = "recall"
+	LoanActionBuyin   LoanAction = "buy in"
+	LoanActionSettle  LoanAction = "settle"
+)
+
+// loanTransitions lists, for each loan status, the actions allowed from it
+// and the status the loan moves to. A proposed loan is approved by the
+// counterparty and is PENDING until it settles, when it becomes OPEN.
+// Statuses without actions are final
+var loanTransitions = map[LoanStatus]map[LoanAction]LoanStatus{
+	LoanStatusProposed: {
+		LoanActionApprove: LoanStatusPending,
+		LoanActionCancel:  LoanStatusCanceled,
+		LoanActionDecline: LoanStatusDeclined,
+	},
+	LoanStatusPending: {
+		LoanActionSettle: LoanStatusOpen,
+	},
+	LoanStatusOpen: {
+		LoanActionSettle: LoanStatusOpen,
+		LoanActionRerate: LoanStatusOpen,
+		LoanActionReturn: LoanStatusOpen,
+		LoanActionRecall: LoanStatusOpen,
+		LoanActionBuyin:  LoanStatusOpen,
+	},
+}
+
+// settlementTransitions lists the settlement statuses reachable from each
+// settlement status
+var settlementTransitions = map[SettlementStatus][]SettlementStatus{
+	SettlementStatusNone:    {SettlementStatusPending, SettlementStatusSettled},
+	SettlementStatusPending: {SettlementStatusSettled, SettlementStatusFailed},
+	SettlementStatusFailed:  {SettlementStatusPending, SettlementStatusSettled},
+}
+
+// Next returns the status a loan in status s moves to with the action, and
+// false if the action is not allowed from s
+func (s LoanStatus) Next(action LoanAction) (LoanStatus, bool) {
+	next, ok := loanTransitions[s][action]
+	return next, ok
+}
+
+// Allows reports whether the action is allowed on a loan in status s
+func (s LoanStatus) Allows(action LoanAction) bool {
+	_, ok := s.Next(action)
+	return ok
+}
+
+// AllowedActions returns the actions allowed on a loan in status s, sorted
+func (s LoanStatus) AllowedActions() []LoanAction {
+	var actions []LoanAction
+	for action := range loanTransitions[s] {
+		actions = append(actions, action)
+	}
+
+	slices.Sort(actions)
+
+	return actions
+}
+
+// IsFinal reports whether no further action is possible on a loan in status s
+func (s LoanStatus) IsFinal() bool {
+	return len(loanTransitions[s]) == 0
+}
+
+// CanTransition reports whether a settlement status s can move to next
+func (s SettlementStatus) CanTransition(next SettlementStatus) bool {
+	// A loan without a settlement status yet is treated as NONE
+	if s == "" {
+		s = SettlementStatusNone
+	}
+
+	return slices.Contains(settlementTransitions[s], next)
+}
+
+// TransitionError is returned when an action is not allowed from the
+// current status of a loan. It explains which actions are allowed instead
+type TransitionError struct {
+	LoanId  string
+	Status  LoanStatus
+	Action  LoanAction
+	Allowed []LoanAction
+}
+
+// Error implements the error interface
+func (e *TransitionError) Error() string {
+	msg := fmt.Sprintf("cannot %s loan [%s] in %s state", e.Action, e.LoanId, e.Status)
+
+	if len(e.Allowed) == 0 {
+		return msg + "; no action is allowed from " + string(e.Status)
+	}
+
+	allowed := make([]string, len(e.Allowed))
+	for i, action := range e.Allowed {
+		allowed[i] = string(action)
+	}
+
+	return msg + "; allowed from " + string(e.Status) + ": " + strings.Join(allowed, ", ")
+}
+
+// CheckAction checks the action against the loan's current status, returning
+// a *TransitionError if it is not allowed
+func (l *Loan) CheckAction(action LoanAction) error {
+	if l.LoanStatus.Allows(action) {
+		return nil
+	}
+
+	return &TransitionError{
+		LoanId:  l.LoanId,
+		Status:  l.LoanStatus,
+		Action:  action,
+		Allowed: l.LoanStatus.AllowedActions(),
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var allLoanActions = []LoanAction{
+	LoanActionApprove, LoanActionCancel, LoanActionDecline, LoanActionRerate,
+	LoanActionReturn, LoanActionRecall, LoanActionBuyin, LoanActionSettle,
+}
+
+func TestLoanStatusNext(t *testing.T) {
+	tests := []struct {
+		status LoanStatus
+		action LoanAction
+		want   LoanStatus
+		ok     bool
+	}{
+		{LoanStatusProposed, LoanActionApprove, LoanStatusPending, true},
+		{LoanStatusProposed, LoanActionCancel, LoanStatusCanceled, true},
+		{LoanStatusProposed, LoanActionDecline, LoanStatusDeclined, true},
+		{LoanStatusProposed, LoanActionSettle, "", false},
+		{LoanStatusProposed, LoanActionReturn, "", false},
+		{LoanStatusPending, LoanActionSettle, LoanStatusOpen, true},
+		{LoanStatusPending, LoanActionCancel, "", false},
+		{LoanStatusPending, LoanActionRerate, "", false},
+		{LoanStatusOpen, LoanActionSettle, LoanStatusOpen, true},
+		{LoanStatusOpen, LoanActionRerate, LoanStatusOpen, true},
+		{LoanStatusOpen, LoanActionReturn, LoanStatusOpen, true},
+		{LoanStatusOpen, LoanActionRecall, LoanStatusOpen, true},
+		{LoanStatusOpen, LoanActionBuyin, LoanStatusOpen, true},
+		{LoanStatusOpen, LoanActionApprove, "", false},
+		{LoanStatusOpen, LoanActionDecline, "", false},
+		{LoanStatusClosed, LoanActionReturn, "", false},
+		{LoanStatusCanceled, LoanActionApprove, "", false},
+		{LoanStatusDeclined, LoanActionApprove, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.status.Next(tt.action)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s.Next(%s) = %q, %v, want %q, %v", tt.status, tt.action, got, ok, tt.want, tt.ok)
+		}
+
+		if allows := tt.status.Allows(tt.action); allows != tt.ok {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.status, tt.action, allows, tt.ok)
+		}
+	}
+}
+
+func TestLoanStatusFinal(t *testing.T) {
+	tests := []struct {
+		status  LoanStatus
+		final   bool
+		allowed []LoanAction
+	}{
+		{LoanStatusProposed, false, []LoanAction{LoanActionApprove, LoanActionCancel, LoanActionDecline}},
+		{LoanStatusPending, false, []LoanAction{LoanActionSettle}},
+		{LoanStatusOpen, false, []LoanAction{LoanActionBuyin, LoanActionRecall, LoanActionRerate, LoanActionReturn, LoanActionSettle}},
+		{LoanStatusCanceled, true, nil},
+		{LoanStatusDeclined, true, nil},
+		{LoanStatusClosed, true, nil},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.IsFinal(); got != tt.final {
+			t.Errorf("%s.IsFinal() = %v, want %v", tt.status, got, tt.final)
+		}
+
+		if got := tt.status.AllowedActions(); !slices.Equal(got, tt.allowed) {
+			t.Errorf("%s.AllowedActions() = %v, want %v", tt.status, got, tt.allowed)
+		}
+
+		for _, action := range allLoanActions {
+			if tt.status.Allows(action) != slices.Contains(tt.allowed, action) {
+				t.Errorf("%s.Allows(%s) disagrees with AllowedActions", tt.status, action)
+			}
+		}
+	}
+}
+
+func TestSettlementStatusCanTransition(t *testing.T) {
+	tests := []struct {
+		from SettlementStatus
+		to   SettlementStatus
+		want bool
+	}{
+		{"", SettlementStatusPending, true},
+		{"", SettlementStatusFailed, false},
+		{SettlementStatusNone, SettlementStatusSettled, true},
+		{SettlementStatusPending, SettlementStatusSettled, true},
+		{SettlementStatusPending, SettlementStatusFailed, true},
+		{SettlementStatusPending, SettlementStatusNone, false},
+		{SettlementStatusFailed, SettlementStatusPending, true},
+		{SettlementStatusSettled, SettlementStatusPending, false},
+		{SettlementStatusSettled, SettlementStatusFailed, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransition(tt.to); got != tt.want {
+			t.Errorf("%q.CanTransition(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAction(t *testing.T) {
+	tests := []struct {
+		status LoanStatus
+		action LoanAction
+		want   string
+	}{
+		{LoanStatusProposed, LoanActionApprove, ""},
+		{LoanStatusOpen, LoanActionReturn, ""},
+		{LoanStatusProposed, LoanActionReturn, "cannot return loan [L1] in PROPOSED state; allowed from PROPOSED: approve, cancel, decline"},
+		{LoanStatusPending, LoanActionCancel, "cannot cancel loan [L1] in PENDING state; allowed from PENDING: settle"},
+		{LoanStatusOpen, LoanActionDecline, "cannot decline loan [L1] in OPEN state; allowed from OPEN: buy in, recall, rerate, return, settle"},
+		{LoanStatusClosed, LoanActionRecall, "cannot recall loan [L1] in CLOSED state; no action is allowed from CLOSED"},
+		{LoanStatusCanceled, LoanActionApprove, "cannot approve loan [L1] in CANCELED state; no action is allowed from CANCELED"},
+	}
+
+	for _, tt := range tests {
+		loan := &Loan{LoanId: "L1", LoanStatus: tt.status}
+		err := loan.CheckAction(tt.action)
+
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("CheckAction(%s) on %s = %v, want nil", tt.action, tt.status, err)
+			}
+			continue
+		}
+
+		var transition *TransitionError
+		if !errors.As(err, &transition) {
+			t.Fatalf("CheckAction(%s) on %s = %v, want a *TransitionError", tt.action, tt.status, err)
+		}
+
+		if transition.Status != tt.status || transition.Action != tt.action {
+			t.Errorf("TransitionError = %+v, want status %s and action %s", transition, tt.status, tt.action)
+		}
+
+		if err.Error() != tt.want {
+			t.Errorf("CheckAction(%s) on %s:\n got %q\nwant %q", tt.action, tt.status, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// Package models contains the models for the application
+//
+// The models of the 1Source REST API in models_gen.go are generated from the
+// checked-in copy of the 1Source OpenAPI document in openapi/. After updating
+// that document, regenerate them with:
+//
+//	go generate ./models
+package models
+
+//go:generate go run ../internal/modelgen -spec ../openapi/1source-v1.1.json -out models_gen.go
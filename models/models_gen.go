@@ -0,0 +1,642 @@
+// Code generated by modelgen from ../openapi/1source-v1.1.json; DO NOT EDIT.
+
+// 1Source Ledger API 1.1.0
+
+package models
+
+// Party A legal entity participating in 1Source
+type Party struct {
+	// 1Source identifier of the party
+	PartyId string `json:"partyId"`
+	// Name of the party
+	PartyName string `json:"partyName,omitempty"`
+	// GLEIF Legal Entity Identifier of the party
+	GleifLei string `json:"gleifLei,omitempty"`
+	// Identifier of the party in the caller's own systems
+	InternalPartyId string `json:"internalPartyId,omitempty"`
+}
+
+// Parties A list of parties
+type Parties []Party
+
+// PartyRole Role of a party in a loan
+type PartyRole string
+
+// PartyRole values
+const (
+	PartyRoleBorrower PartyRole = "BORROWER"
+	PartyRoleLender   PartyRole = "LENDER"
+)
+
+// TransactingParty A party to a trade and its role
+type TransactingParty struct {
+	PartyRole PartyRole `json:"partyRole"`
+	Party     Party     `json:"party"`
+}
+
+// VenueParty A party as known to the execution venue
+type VenueParty struct {
+	PartyRole PartyRole `json:"partyRole"`
+	// Identifier of the party at the execution venue
+	VenueId string `json:"venueId,omitempty"`
+}
+
+// VenueType Where the trade was executed
+type VenueType string
+
+// VenueType values
+const (
+	VenueTypeOnplatform  VenueType = "ONPLATFORM"
+	VenueTypeOffplatform VenueType = "OFFPLATFORM"
+)
+
+// Platform The trading platform a trade was executed on
+type Platform struct {
+	// GLEIF Legal Entity Identifier of the platform
+	GleifLei string `json:"gleifLei,omitempty"`
+	// Legal name of the platform
+	LegalName string `json:"legalName,omitempty"`
+	// Name of the venue
+	VenueName string `json:"venueName"`
+	// Reference of the trade at the venue
+	VenueRefId string `json:"venueRefId"`
+	// When the trade was executed at the venue
+	TransactionDatetime string `json:"transactionDatetime,omitempty"`
+}
+
+// ExecutionVenue The venue a trade or lifecycle event was executed on
+type ExecutionVenue struct {
+	Type         VenueType    `json:"type"`
+	Platform     *Platform    `json:"platform,omitempty"`
+	VenueParties []VenueParty `json:"venueParties,omitempty"`
+}
+
+// PriceUnit Unit a price is quoted in
+type PriceUnit string
+
+// PriceUnit values
+const (
+	PriceUnitShare PriceUnit = "SHARE"
+	PriceUnitLot   PriceUnit = "LOT"
+)
+
+// Price A price of an instrument
+type Price struct {
+	// Price value
+	Value float64 `json:"value"`
+	// ISO 4217 currency code
+	Currency string    `json:"currency"`
+	Unit     PriceUnit `json:"unit,omitempty"`
+}
+
+// Instrument The security on loan
+type Instrument struct {
+	// Exchange ticker
+	Ticker string `json:"ticker,omitempty"`
+	// CUSIP identifier
+	Cusip string `json:"cusip,omitempty"`
+	// ISIN identifier
+	Isin string `json:"isin,omitempty"`
+	// SEDOL identifier
+	Sedol string `json:"sedol,omitempty"`
+	// QUICK identifier
+	Quick string `json:"quick,omitempty"`
+	// FIGI identifier
+	Figi string `json:"figi,omitempty"`
+	// Description of the security
+	Description string `json:"description,omitempty"`
+	Price       *Price `json:"price,omitempty"`
+}
+
+// FixedRate A fixed rate
+type FixedRate struct {
+	// Base rate in percent
+	BaseRate float64 `json:"baseRate"`
+	// Effective rate in percent
+	EffectiveRate float64 `json:"effectiveRate,omitempty"`
+	// Date the rate is effective from
+	EffectiveDate string `json:"effectiveDate,omitempty"`
+	// Cutoff time of the rate
+	CutoffTime string `json:"cutoffTime,omitempty"`
+}
+
+// FloatingRate A floating rate, a spread over a benchmark
+type FloatingRate struct {
+	// Benchmark the rate floats over, for example OBFR
+	Benchmark string `json:"benchmark"`
+	// Benchmark rate in percent
+	BaseRate float64 `json:"baseRate,omitempty"`
+	// Spread over the benchmark in percent
+	Spread float64 `json:"spread"`
+	// Effective rate in percent
+	EffectiveRate float64 `json:"effectiveRate,omitempty"`
+	// Whether the rate is rerated automatically with the benchmark
+	IsAutoRerate bool `json:"isAutoRerate,omitempty"`
+	// Days between a benchmark change and its effective date
+	EffectiveDateDelay *int32 `json:"effectiveDateDelay,omitempty"`
+	// Date the rate is effective from
+	EffectiveDate string `json:"effectiveDate,omitempty"`
+	// Cutoff time of the rate
+	CutoffTime string `json:"cutoffTime,omitempty"`
+}
+
+// RebateRate A rebate rate, either fixed or floating
+type RebateRate struct {
+	Fixed    *FixedRate    `json:"fixed,omitempty"`
+	Floating *FloatingRate `json:"floating,omitempty"`
+}
+
+// FeeRate A fee rate
+type FeeRate struct {
+	// Base rate in percent
+	BaseRate float64 `json:"baseRate"`
+	// Effective rate in percent
+	EffectiveRate float64 `json:"effectiveRate,omitempty"`
+	// Date the rate is effective from
+	EffectiveDate string `json:"effectiveDate,omitempty"`
+	// Cutoff time of the rate
+	CutoffTime string `json:"cutoffTime,omitempty"`
+}
+
+// Rate The rate of a loan, either a rebate or a fee
+type Rate struct {
+	Rebate *RebateRate `json:"rebate,omitempty"`
+	Fee    *FeeRate    `json:"fee,omitempty"`
+}
+
+// TermType Term of a loan
+type TermType string
+
+// TermType values
+const (
+	TermTypeOpen  TermType = "OPEN"
+	TermTypeTerm  TermType = "TERM"
+	TermTypeFixed TermType = "FIXED"
+)
+
+// SettlementType How a loan settles
+type SettlementType string
+
+// SettlementType values
+const (
+	SettlementTypeDvp SettlementType = "DVP"
+	SettlementTypeFop SettlementType = "FOP"
+)
+
+// CollateralType Type of collateral
+type CollateralType string
+
+// CollateralType values
+const (
+	CollateralTypeCash    CollateralType = "CASH"
+	CollateralTypeNoncash CollateralType = "NONCASH"
+)
+
+// RoundingMode How collateral values are rounded
+type RoundingMode string
+
+// RoundingMode values
+const (
+	RoundingModeAlwaysup   RoundingMode = "ALWAYSUP"
+	RoundingModeAlwaysdown RoundingMode = "ALWAYSDOWN"
+)
+
+// Collateral The collateral of a loan
+type Collateral struct {
+	// Price of the security used to value the loan
+	ContractPrice float64 `json:"contractPrice,omitempty"`
+	// Value of the loan
+	ContractValue float64 `json:"contractValue,omitempty"`
+	// Value of the collateral
+	CollateralValue float64 `json:"collateralValue"`
+	// ISO 4217 currency code of the collateral
+	Currency string         `json:"currency"`
+	Type     CollateralType `json:"type"`
+	// Description code of non-cash collateral, for example NONUSAGENCIES
+	DescriptionCd string `json:"descriptionCd,omitempty"`
+	// Collateral margin in percent
+	Margin float64 `json:"margin,omitempty"`
+	// Rounding rule applied to the collateral value
+	RoundingRule *int32       `json:"roundingRule,omitempty"`
+	RoundingMode RoundingMode `json:"roundingMode,omitempty"`
+}
+
+// Trade The economic terms of a loan or trade agreement
+type Trade struct {
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	Instrument     Instrument      `json:"instrument"`
+	Rate           Rate            `json:"rate"`
+	// Quantity of securities on loan
+	Quantity int64 `json:"quantity"`
+	// Quantity of securities still on loan after returns
+	OpenQuantity *int64 `json:"openQuantity,omitempty"`
+	// ISO 4217 currency code the loan is billed in
+	BillingCurrency string `json:"billingCurrency"`
+	// Dividend rate in percent
+	DividendRatePct float64 `json:"dividendRatePct"`
+	// Trade date
+	TradeDate string   `json:"tradeDate"`
+	TermType  TermType `json:"termType,omitempty"`
+	// Term date of TERM loans
+	TermDate string `json:"termDate,omitempty"`
+	// Settlement date
+	SettlementDate     string             `json:"settlementDate,omitempty"`
+	SettlementType     SettlementType     `json:"settlementType"`
+	Collateral         Collateral         `json:"collateral"`
+	TransactingParties []TransactingParty `json:"transactingParties"`
+}
+
+// LocalMarketField A market specific settlement field
+type LocalMarketField struct {
+	// Name of the field
+	LocalFieldName string `json:"localFieldName"`
+	// Value of the field
+	LocalFieldValue string `json:"localFieldValue"`
+}
+
+// SettlementInstruction Settlement instruction of one side of a loan
+type SettlementInstruction struct {
+	// BIC of the settlement agent
+	SettlementBic string `json:"settlementBic"`
+	// BIC of the local agent
+	LocalAgentBic string `json:"localAgentBic"`
+	// Name of the local agent
+	LocalAgentName string `json:"localAgentName,omitempty"`
+	// Account at the local agent
+	LocalAgentAcct string `json:"localAgentAcct"`
+	// DTC participant number
+	DtcParticipantNumber string `json:"dtcParticipantNumber,omitempty"`
+	// CDS customer unit identifier
+	CdsCustomerUnitId string `json:"cdsCustomerUnitId,omitempty"`
+	// BIC of the custodian
+	CustodianBic string `json:"custodianBic,omitempty"`
+	// Name of the custodian
+	CustodianName string `json:"custodianName,omitempty"`
+	// Account at the custodian
+	CustodianAcct     string             `json:"custodianAcct,omitempty"`
+	LocalMarketFields []LocalMarketField `json:"localMarketFields,omitempty"`
+}
+
+// PartySettlementInstruction Settlement instruction of a party role
+type PartySettlementInstruction struct {
+	PartyRole PartyRole `json:"partyRole"`
+	// Account code in the party's own systems
+	InternalAcctCd string                `json:"internalAcctCd,omitempty"`
+	Instruction    SettlementInstruction `json:"instruction"`
+}
+
+// LoanStatus Lifecycle status of a loan
+type LoanStatus string
+
+// LoanStatus values
+const (
+	LoanStatusProposed LoanStatus = "PROPOSED"
+	LoanStatusPending  LoanStatus = "PENDING"
+	LoanStatusOpen     LoanStatus = "OPEN"
+	LoanStatusCanceled LoanStatus = "CANCELED"
+	LoanStatusDeclined LoanStatus = "DECLINED"
+	LoanStatusClosed   LoanStatus = "CLOSED"
+)
+
+// SettlementStatus Settlement status of a loan or return
+type SettlementStatus string
+
+// SettlementStatus values
+const (
+	SettlementStatusNone    SettlementStatus = "NONE"
+	SettlementStatusPending SettlementStatus = "PENDING"
+	SettlementStatusSettled SettlementStatus = "SETTLED"
+	SettlementStatusFailed  SettlementStatus = "FAILED"
+)
+
+// Loan A securities loan contract
+type Loan struct {
+	// 1Source identifier of the loan
+	LoanId string `json:"loanId"`
+	// Identifier of the last event which changed the loan
+	LastEventId      uint64           `json:"lastEventId,omitempty"`
+	LoanStatus       LoanStatus       `json:"loanStatus"`
+	SettlementStatus SettlementStatus `json:"settlementStatus,omitempty"`
+	// Party which made the last change
+	LastUpdatePartyId string `json:"lastUpdatePartyId,omitempty"`
+	// When the loan was last changed
+	LastUpdateDateTime string                       `json:"lastUpdateDateTime,omitempty"`
+	Trade              Trade                        `json:"trade"`
+	Settlement         []PartySettlementInstruction `json:"settlement,omitempty"`
+}
+
+// Loans A list of loans
+type Loans []Loan
+
+// LoanProposal Body of a loan proposal
+type LoanProposal struct {
+	Trade      Trade                        `json:"trade"`
+	Settlement []PartySettlementInstruction `json:"settlement"`
+}
+
+// LoanProposalApproval Body of the approval of a proposed loan by the counterparty
+type LoanProposalApproval struct {
+	Settlement PartySettlementInstruction `json:"settlement"`
+	// Rounding rule applied to the collateral value
+	RoundingRule *int32       `json:"roundingRule,omitempty"`
+	RoundingMode RoundingMode `json:"roundingMode,omitempty"`
+}
+
+// LoanSettlementStatusUpdate Body of an update of the settlement status of a loan
+type LoanSettlementStatusUpdate struct {
+	SettlementStatus SettlementStatus `json:"settlementStatus"`
+}
+
+// SettlementInstructionUpdate Body of an update of one side's settlement instruction
+type SettlementInstructionUpdate struct {
+	// Reference of the update at the venue
+	VenueRefId  string                `json:"venueRefId,omitempty"`
+	PartyRole   PartyRole             `json:"partyRole"`
+	Instruction SettlementInstruction `json:"instruction"`
+}
+
+// AgreementStatus Status of a trade agreement
+type AgreementStatus string
+
+// AgreementStatus values
+const (
+	AgreementStatusProposed  AgreementStatus = "PROPOSED"
+	AgreementStatusConfirmed AgreementStatus = "CONFIRMED"
+	AgreementStatusCanceled  AgreementStatus = "CANCELED"
+)
+
+// Agreement A trade agreement executed on a venue
+type Agreement struct {
+	// 1Source identifier of the trade agreement
+	AgreementId string          `json:"agreementId"`
+	Status      AgreementStatus `json:"status,omitempty"`
+	// Identifier of the last event which changed the agreement
+	LastEventId uint64 `json:"lastEventId,omitempty"`
+	// When the agreement was last changed
+	LastUpdateDateTime string `json:"lastUpdateDateTime,omitempty"`
+	Trade              Trade  `json:"trade"`
+}
+
+// Agreements A list of trade agreements
+type Agreements []Agreement
+
+// RerateStatus Lifecycle status of a rerate
+type RerateStatus string
+
+// RerateStatus values
+const (
+	RerateStatusProposed RerateStatus = "PROPOSED"
+	RerateStatusPending  RerateStatus = "PENDING"
+	RerateStatusApproved RerateStatus = "APPROVED"
+	RerateStatusApplied  RerateStatus = "APPLIED"
+	RerateStatusDeclined RerateStatus = "DECLINED"
+	RerateStatusCanceled RerateStatus = "CANCELED"
+)
+
+// Rerate A change of the rate of a loan
+type Rerate struct {
+	// 1Source identifier of the rerate
+	RerateId string `json:"rerateId"`
+	// Loan the rerate applies to
+	LoanId         string          `json:"loanId"`
+	Status         RerateStatus    `json:"status"`
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	Rate           *Rate           `json:"rate,omitempty"`
+	Rerate         Rate            `json:"rerate"`
+	// Identifier of the last event which changed the rerate
+	LastEventId uint64 `json:"lastEventId,omitempty"`
+	// When the rerate was proposed
+	DateCreated string `json:"dateCreated,omitempty"`
+	// When the rerate was last changed
+	LastUpdateDateTime string `json:"lastUpdateDateTime,omitempty"`
+}
+
+// Rerates A list of rerates
+type Rerates []Rerate
+
+// RerateProposal Body of a rerate proposal
+type RerateProposal struct {
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	Rerate         Rate            `json:"rerate"`
+}
+
+// ReturnStatus Lifecycle status of a return
+type ReturnStatus string
+
+// ReturnStatus values
+const (
+	ReturnStatusPending      ReturnStatus = "PENDING"
+	ReturnStatusAcknowledged ReturnStatus = "ACKNOWLEDGED"
+	ReturnStatusSettled      ReturnStatus = "SETTLED"
+	ReturnStatusCanceled     ReturnStatus = "CANCELED"
+)
+
+// AcknowledgementType Type of a return acknowledgement
+type AcknowledgementType string
+
+// AcknowledgementType values
+const (
+	AcknowledgementTypePositive AcknowledgementType = "POSITIVE"
+	AcknowledgementTypeNegative AcknowledgementType = "NEGATIVE"
+)
+
+// ReturnAcknowledgement Acknowledgement of a return by the counterparty
+type ReturnAcknowledgement struct {
+	AcknowledgementType AcknowledgementType `json:"acknowledgementType"`
+	// Reason for a negative acknowledgement
+	Description string                      `json:"description,omitempty"`
+	Settlement  *PartySettlementInstruction `json:"settlement,omitempty"`
+}
+
+// Return A return of securities on loan
+type Return struct {
+	// 1Source identifier of the return
+	ReturnId string `json:"returnId"`
+	// Loan the return applies to
+	LoanId           string           `json:"loanId"`
+	Status           ReturnStatus     `json:"status"`
+	SettlementStatus SettlementStatus `json:"settlementStatus,omitempty"`
+	ExecutionVenue   *ExecutionVenue  `json:"executionVenue,omitempty"`
+	// Quantity of securities returned
+	Quantity       int64          `json:"quantity"`
+	Collateral     *Collateral    `json:"collateral,omitempty"`
+	SettlementType SettlementType `json:"settlementType,omitempty"`
+	// Date of the return
+	ReturnDate string `json:"returnDate,omitempty"`
+	// Settlement date of the return
+	ReturnSettlementDate string                       `json:"returnSettlementDate,omitempty"`
+	Settlement           []PartySettlementInstruction `json:"settlement,omitempty"`
+	Acknowledgements     []ReturnAcknowledgement      `json:"acknowledgements,omitempty"`
+	// Identifier of the last event which changed the return
+	LastEventId uint64 `json:"lastEventId,omitempty"`
+	// When the return was proposed
+	DateCreated string `json:"dateCreated,omitempty"`
+	// When the return was last changed
+	LastUpdateDateTime string `json:"lastUpdateDateTime,omitempty"`
+}
+
+// Returns A list of returns
+type Returns []Return
+
+// ReturnProposal Body of a return proposal
+type ReturnProposal struct {
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	// Quantity of securities to return
+	Quantity int64 `json:"quantity"`
+	// Value of the collateral returned
+	CollateralValue float64        `json:"collateralValue,omitempty"`
+	SettlementType  SettlementType `json:"settlementType,omitempty"`
+	// Date of the return
+	ReturnDate string `json:"returnDate"`
+	// Settlement date of the return
+	ReturnSettlementDate string                       `json:"returnSettlementDate"`
+	Settlement           []PartySettlementInstruction `json:"settlement,omitempty"`
+}
+
+// ReturnSettlementStatusUpdate Body of an update of the settlement status of a return
+type ReturnSettlementStatusUpdate struct {
+	SettlementStatus SettlementStatus `json:"settlementStatus"`
+}
+
+// RecallStatus Lifecycle status of a recall
+type RecallStatus string
+
+// RecallStatus values
+const (
+	RecallStatusOpen     RecallStatus = "OPEN"
+	RecallStatusCanceled RecallStatus = "CANCELED"
+	RecallStatusClosed   RecallStatus = "CLOSED"
+)
+
+// Recall A recall of securities on loan by the lender
+type Recall struct {
+	// 1Source identifier of the recall
+	RecallId string `json:"recallId"`
+	// Loan the recall applies to
+	LoanId         string          `json:"loanId"`
+	Status         RecallStatus    `json:"status"`
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	// Quantity of the recall not yet returned
+	OpenQuantity *int64 `json:"openQuantity,omitempty"`
+	// Quantity of securities recalled
+	Quantity int64 `json:"quantity"`
+	// Date of the recall
+	RecallDate string `json:"recallDate,omitempty"`
+	// Date the recalled securities are due back
+	RecallDueDate string `json:"recallDueDate,omitempty"`
+	// Identifier of the last event which changed the recall
+	LastEventId uint64 `json:"lastEventId,omitempty"`
+	// When the recall was last changed
+	LastUpdateDateTime string `json:"lastUpdateDateTime,omitempty"`
+}
+
+// Recalls A list of recalls
+type Recalls []Recall
+
+// RecallProposal Body of a recall proposal
+type RecallProposal struct {
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	// Quantity of securities to recall
+	Quantity int64 `json:"quantity"`
+	// Date of the recall
+	RecallDate string `json:"recallDate"`
+	// Date the recalled securities are due back
+	RecallDueDate string `json:"recallDueDate"`
+}
+
+// BuyinStatus Lifecycle status of a buy-in
+type BuyinStatus string
+
+// BuyinStatus values
+const (
+	BuyinStatusProposed BuyinStatus = "PROPOSED"
+	BuyinStatusAccepted BuyinStatus = "ACCEPTED"
+	BuyinStatusCanceled BuyinStatus = "CANCELED"
+)
+
+// Buyin A buy-in of securities not returned after a recall
+type Buyin struct {
+	// 1Source identifier of the buy-in
+	BuyinId string `json:"buyinId"`
+	// Loan the buy-in applies to
+	LoanId         string          `json:"loanId"`
+	Status         BuyinStatus     `json:"status"`
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	// Quantity of securities bought in
+	Quantity int64 `json:"quantity"`
+	Price    Price `json:"price"`
+	// Identifier of the last event which changed the buy-in
+	LastEventId uint64 `json:"lastEventId,omitempty"`
+	// When the buy-in was submitted
+	DateCreated string `json:"dateCreated,omitempty"`
+	// When the buy-in was last changed
+	LastUpdateDateTime string `json:"lastUpdateDateTime,omitempty"`
+}
+
+// Buyins A list of buy-ins
+type Buyins []Buyin
+
+// BuyinProposal Body of a buy-in submission
+type BuyinProposal struct {
+	ExecutionVenue *ExecutionVenue `json:"executionVenue,omitempty"`
+	// Quantity of securities bought in
+	Quantity int64 `json:"quantity"`
+	Price    Price `json:"price"`
+}
+
+// EventType Type of a ledger event
+type EventType string
+
+// EventType values
+const (
+	EventTypeTrade                               EventType = "TRADE"
+	EventTypeContractProposed                    EventType = "CONTRACT_PROPOSED"
+	EventTypeContractPending                     EventType = "CONTRACT_PENDING"
+	EventTypeContractOpened                      EventType = "CONTRACT_OPENED"
+	EventTypeContractCanceled                    EventType = "CONTRACT_CANCELED"
+	EventTypeContractDeclined                    EventType = "CONTRACT_DECLINED"
+	EventTypeContractClosed                      EventType = "CONTRACT_CLOSED"
+	EventTypeContractSettlementStatusUpdate      EventType = "CONTRACT_SETTLEMENT_STATUS_UPDATE"
+	EventTypeContractSettlementInstructionUpdate EventType = "CONTRACT_SETTLEMENT_INSTRUCTION_UPDATE"
+	EventTypeRerateProposed                      EventType = "RERATE_PROPOSED"
+	EventTypeReratePending                       EventType = "RERATE_PENDING"
+	EventTypeRerateApproved                      EventType = "RERATE_APPROVED"
+	EventTypeRerateApplied                       EventType = "RERATE_APPLIED"
+	EventTypeRerateDeclined                      EventType = "RERATE_DECLINED"
+	EventTypeRerateCanceled                      EventType = "RERATE_CANCELED"
+	EventTypeReturnPending                       EventType = "RETURN_PENDING"
+	EventTypeReturnAcknowledged                  EventType = "RETURN_ACKNOWLEDGED"
+	EventTypeReturnSettled                       EventType = "RETURN_SETTLED"
+	EventTypeReturnCanceled                      EventType = "RETURN_CANCELED"
+	EventTypeRecallOpened                        EventType = "RECALL_OPENED"
+	EventTypeRecallCanceled                      EventType = "RECALL_CANCELED"
+	EventTypeRecallClosed                        EventType = "RECALL_CLOSED"
+	EventTypeBuyinProposed                       EventType = "BUYIN_PROPOSED"
+	EventTypeBuyinAccepted                       EventType = "BUYIN_ACCEPTED"
+)
+
+// Event A ledger event
+type Event struct {
+	// Monotonically increasing identifier of the event
+	EventId   uint64    `json:"eventId"`
+	EventType EventType `json:"eventType"`
+	// When the event happened
+	EventDateTime string `json:"eventDateTime"`
+	// URI of the entity the event applies to
+	ResourceUri string `json:"resourceUri"`
+}
+
+// Events A list of events
+type Events []Event
+
+// LedgerResponse Response of a ledger operation, also used for errors
+type LedgerResponse struct {
+	// When the response was created
+	Timestamp string `json:"timestamp,omitempty"`
+	// HTTP status code
+	Status *int32 `json:"status,omitempty"`
+	// Message of the ledger
+	Message string `json:"message,omitempty"`
+	// Path of the request
+	Path string `json:"path,omitempty"`
+}
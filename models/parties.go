@@ -1,6 +0,0 @@
-// Package models contains the models for the application
-package models
-
-type (
-	Parties []party
-)
@@ -0,0 +1,55 @@
+// Package models contains the models for the application
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// String describes the rate in one line, for display
+func (r *Rate) String() string {
+	switch {
+	case r == nil:
+		return "none"
+	case r.Rebate != nil && r.Rebate.Fixed != nil:
+		f := r.Rebate.Fixed
+		return withDate(fmt.Sprintf("fixed rebate %g%% (effective %g%%)", f.BaseRate, f.EffectiveRate), f.EffectiveDate)
+	case r.Rebate != nil && r.Rebate.Floating != nil:
+		f := r.Rebate.Floating
+		return withDate(fmt.Sprintf("floating rebate %s %+g%% (effective %g%%)", f.Benchmark, f.Spread, f.EffectiveRate), f.EffectiveDate)
+	case r.Fee != nil:
+		return withDate(fmt.Sprintf("fee %g%% (effective %g%%)", r.Fee.BaseRate, r.Fee.EffectiveRate), r.Fee.EffectiveDate)
+	}
+
+	return "none"
+}
+
+// Validate checks that the rate is either a rebate or a fee, and that a
+// rebate is either fixed or floating
+func (r *Rate) Validate() error {
+	if (r.Rebate == nil) == (r.Fee == nil) {
+		return errors.New("rate must be either a rebate or a fee")
+	}
+
+	if r.Rebate != nil {
+		if (r.Rebate.Fixed == nil) == (r.Rebate.Floating == nil) {
+			return errors.New("rebate rate must be either fixed or floating")
+		}
+
+		if r.Rebate.Floating != nil && r.Rebate.Floating.Benchmark == "" {
+			return errors.New("floating rebate rate requires a benchmark")
+		}
+	}
+
+	return nil
+}
+
+// withDate appends the effective date to a rate description, if set
+func withDate(description string, effectiveDate string) string {
+	if strings.TrimSpace(effectiveDate) == "" {
+		return description
+	}
+
+	return description + " from " + effectiveDate
+}
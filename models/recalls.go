@@ -0,0 +1,28 @@
+// Package models contains the models for the application
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DaysUntilDue returns the number of calendar days from now until the
+// recall's due date, negative once the recall is overdue
+func (r *Recall) DaysUntilDue(now time.Time) (int, error) {
+	due, err := time.Parse(time.DateOnly, r.RecallDueDate)
+	if err != nil {
+		return 0, fmt.Errorf("invalid recall due date '%s' of recall [%s]: %w", r.RecallDueDate, r.RecallId, err)
+	}
+
+	// Compare calendar dates in UTC so that DST changes do not skew the count
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(due.Sub(today).Hours() / 24), nil
+}
+
+// Open returns the quantity of the recall still open, defaulting to the
+// whole quantity when 1Source leaves out the open quantity. An open
+// quantity of zero, once the whole recall has been returned, is kept
+func (r *Recall) Open() int64 {
+	return openQuantity(r.OpenQuantity, r.Quantity)
+}
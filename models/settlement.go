@@ -0,0 +1,40 @@
+// Package models contains the models for the application
+package models
+
+// Amend returns a copy of the settlement instruction with the non-empty
+// fields of amendment applied over it. Local market fields are replaced as
+// a whole when the amendment has any
+func (s SettlementInstruction) Amend(amendment SettlementInstruction) SettlementInstruction {
+	amend := func(field *string, value string) {
+		if value != "" {
+			*field = value
+		}
+	}
+
+	amend(&s.SettlementBic, amendment.SettlementBic)
+	amend(&s.LocalAgentBic, amendment.LocalAgentBic)
+	amend(&s.LocalAgentName, amendment.LocalAgentName)
+	amend(&s.LocalAgentAcct, amendment.LocalAgentAcct)
+	amend(&s.DtcParticipantNumber, amendment.DtcParticipantNumber)
+	amend(&s.CdsCustomerUnitId, amendment.CdsCustomerUnitId)
+	amend(&s.CustodianBic, amendment.CustodianBic)
+	amend(&s.CustodianName, amendment.CustodianName)
+	amend(&s.CustodianAcct, amendment.CustodianAcct)
+
+	if len(amendment.LocalMarketFields) > 0 {
+		s.LocalMarketFields = amendment.LocalMarketFields
+	}
+
+	return s
+}
+
+// Instruction returns the settlement instruction of a party role in the loan
+func (l *Loan) Instruction(role PartyRole) (*PartySettlementInstruction, bool) {
+	for i := range l.Settlement {
+		if l.Settlement[i].PartyRole == role {
+			return &l.Settlement[i], true
+		}
+	}
+
+	return nil, false
+}
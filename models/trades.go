@@ -0,0 +1,30 @@
+// Package models contains the models for the application
+package models
+
+// PartyRole returns the role of the party with the given party_id in the
+// trade, and false if it is not one of the transacting parties
+func (t *Trade) PartyRole(partyId string) (PartyRole, bool) {
+	for _, tp := range t.TransactingParties {
+		if tp.Party.PartyId == partyId {
+			return tp.PartyRole, true
+		}
+	}
+
+	return "", false
+}
+
+// Open returns the quantity of the trade still open, defaulting to the
+// whole quantity when 1Source leaves out the open quantity. An open
+// quantity of zero, once the whole trade has been returned, is kept
+func (t *Trade) Open() int64 {
+	return openQuantity(t.OpenQuantity, t.Quantity)
+}
+
+// openQuantity returns open, or quantity when no open quantity was sent
+func openQuantity(open *int64, quantity int64) int64 {
+	if open == nil {
+		return quantity
+	}
+
+	return *open
+}
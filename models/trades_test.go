@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want int64
+	}{
+		{"open quantity left out", `{"quantity": 1000}`, 1000},
+		{"open quantity null", `{"quantity": 1000, "openQuantity": null}`, 1000},
+		{"partly returned", `{"quantity": 1000, "openQuantity": 400}`, 400},
+		{"fully returned", `{"quantity": 1000, "openQuantity": 0}`, 0},
+	}
+
+	for _, tt := range tests {
+		var trade Trade
+		if err := json.Unmarshal([]byte(tt.json), &trade); err != nil {
+			t.Fatalf("%s: decoding trade: %v", tt.name, err)
+		}
+		if got := trade.Open(); got != tt.want {
+			t.Errorf("%s: Trade.Open() = %d, want %d", tt.name, got, tt.want)
+		}
+
+		var recall Recall
+		if err := json.Unmarshal([]byte(tt.json), &recall); err != nil {
+			t.Fatalf("%s: decoding recall: %v", tt.name, err)
+		}
+		if got := recall.Open(); got != tt.want {
+			t.Errorf("%s: Recall.Open() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOpenQuantityEncoding(t *testing.T) {
+	zero := int64(0)
+
+	tests := []struct {
+		open *int64
+		sent bool
+	}{
+		{nil, false},
+		{&zero, true},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(Recall{Quantity: 1000, OpenQuantity: tt.open})
+		if err != nil {
+			t.Fatalf("encoding recall: %v", err)
+		}
+
+		if sent := strings.Contains(string(data), `"openQuantity":0`); sent != tt.sent {
+			t.Errorf("recall encodes as %s, want the open quantity sent %v", data, tt.sent)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package models contains the models for the application
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Validate checks that the loan proposal carries the trade terms, both
+// transacting parties and valid settlement instructions
+func (p *LoanProposal) Validate() error {
+	t := &p.Trade
+
+	if t.Quantity <= 0 {
+		return errors.New("loan proposal quantity must be positive")
+	}
+
+	i := t.Instrument
+	if i.Ticker == "" && i.Cusip == "" && i.Isin == "" && i.Sedol == "" && i.Figi == "" {
+		return errors.New("loan proposal instrument requires an identifier")
+	}
+
+	if err := t.Rate.Validate(); err != nil {
+		return fmt.Errorf("loan proposal: %w", err)
+	}
+
+	for _, role := range []PartyRole{PartyRoleBorrower, PartyRoleLender} {
+		if !slices.ContainsFunc(t.TransactingParties, func(tp TransactingParty) bool { return tp.PartyRole == role }) {
+			return fmt.Errorf("loan proposal has no %s transacting party", role)
+		}
+	}
+
+	if len(p.Settlement) == 0 {
+		return errors.New("loan proposal requires a settlement instruction")
+	}
+
+	for _, s := range p.Settlement {
+		if err := s.Instruction.Validate(); err != nil {
+			return fmt.Errorf("%s %w", s.PartyRole, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the settlement instruction carries the fields
+// required by the 1Source REST API
+func (s *SettlementInstruction) Validate() error {
+	var missing []string
+
+	if s.SettlementBic == "" {
+		missing = append(missing, "settlementBic")
+	}
+	if s.LocalAgentBic == "" {
+		missing = append(missing, "localAgentBic")
+	}
+	if s.LocalAgentAcct == "" {
+		missing = append(missing, "localAgentAcct")
+	}
+
+	for i, field := range s.LocalMarketFields {
+		if field.LocalFieldName == "" || field.LocalFieldValue == "" {
+			missing = append(missing, fmt.Sprintf("localMarketFields[%d]", i))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("settlement instruction is missing %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// Validate checks that the settlement instruction update names the party
+// role it replaces and carries a valid settlement instruction
+func (u *SettlementInstructionUpdate) Validate() error {
+	if u.PartyRole != PartyRoleBorrower && u.PartyRole != PartyRoleLender {
+		return fmt.Errorf("settlement instruction update has unknown party role %q", u.PartyRole)
+	}
+
+	return u.Instruction.Validate()
+}
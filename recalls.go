@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/EquiLend/1Source-Go/utils"
+)
+
+// proposeRecall proposes a recall of securities on a loan_id
+func proposeRecall(ctx context.Context, loanId string, options []string) {
+	fs := flag.NewFlagSet("-rcp", flag.ContinueOnError)
+	quantity := fs.Int64("quantity", 0, "quantity of securities to recall [required]")
+	recallDate := fs.String("recall-date", utils.Today(), "date of the recall (YYYY-MM-DD)")
+	dueDate := fs.String("recall-due-date", "", "date the securities are due back (YYYY-MM-DD) [required]")
+	parseOptions(fs, options)
+
+	if *dueDate == "" {
+		fmt.Println("Error proposing recall: --recall-due-date is required")
+		return
+	}
+
+	if err := utils.ValidateDates(*recallDate, *dueDate); err != nil {
+		fmt.Println("Error proposing recall: ", err)
+		return
+	}
+
+	proposal := models.RecallProposal{
+		Quantity:      *quantity,
+		RecallDate:    *recallDate,
+		RecallDueDate: *dueDate,
+	}
+
+	resp, err := client.ProposeRecall(ctx, loanId, proposal)
+	printOutcome(resp, err, "Error proposing recall: ")
+}
+
+// cancelRecall cancels an open recall
+func cancelRecall(ctx context.Context, recallId string) {
+	resp, err := client.CancelRecall(ctx, recallId)
+	printOutcome(resp, err, "Error canceling recall: ")
+}
+
+// listOpenRecalls prints the open recalls of a loan_id, or of all loans when
+// the entity is "all", with the number of days left until each is due
+func listOpenRecalls(ctx context.Context, entity string) {
+	loanId := entity
+	if entity == "all" {
+		loanId = ""
+	}
+
+	recalls, err := client.ListOpenRecalls(ctx, loanId)
+	if err != nil {
+		log.Println("Error retrieving open recalls: ", err)
+		fmt.Println("Error retrieving open recalls: ", err)
+		return
+	}
+
+	header := "1Source Open Recalls"
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("=", len(header)))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RECALL ID\tLOAN ID\tQUANTITY\tOPEN QUANTITY\tRECALL DATE\tDUE DATE\tDAYS LEFT")
+
+	now := time.Now()
+	for _, r := range recalls {
+		daysLeft := "?"
+		if days, err := r.DaysUntilDue(now); err == nil {
+			daysLeft = fmt.Sprint(days)
+			if days < 0 {
+				daysLeft += " (overdue)"
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\n", r.RecallId, r.LoanId, r.Quantity, r.Open(), r.RecallDate, r.RecallDueDate, daysLeft)
+	}
+
+	w.Flush()
+}
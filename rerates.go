@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/EquiLend/1Source-Go/utils"
+)
+
+// proposeRerate proposes a new fixed rate or floating spread for a loan_id,
+// showing the loan's current rate next to the proposed one first
+func proposeRerate(ctx context.Context, loanId string, options []string) {
+	fs := flag.NewFlagSet("-rrp", flag.ContinueOnError)
+	rate := fs.Float64("rate", 0, "new fixed rebate rate, or fee rate for fee loans, in percent")
+	effectiveRate := fs.Float64("effective-rate", 0, "new effective rate in percent, defaults to --rate")
+	spread := fs.Float64("spread", 0, "new floating rebate spread over the benchmark in percent")
+	benchmark := fs.String("benchmark", "", "benchmark of a floating rebate, defaults to the loan's")
+	effectiveDate := fs.String("effective-date", utils.Today(), "date the new rate is effective from (YYYY-MM-DD)")
+	parseOptions(fs, options)
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if set["rate"] == set["spread"] {
+		fmt.Println("Error proposing rerate: exactly one of --rate or --spread is required")
+		return
+	}
+
+	if err := utils.ValidateDates(*effectiveDate); err != nil {
+		fmt.Println("Error proposing rerate: ", err)
+		return
+	}
+
+	loan, err := client.GetLoan(ctx, loanId)
+	if err != nil {
+		log.Println("Error retrieving loan: ", err)
+		fmt.Println("Error retrieving loan: ", err)
+		return
+	}
+
+	current := loan.Trade.Rate
+	proposed := models.Rate{}
+
+	switch {
+	case set["spread"]:
+		floating := &models.FloatingRate{
+			Benchmark:     *benchmark,
+			Spread:        *spread,
+			EffectiveDate: *effectiveDate,
+		}
+
+		if current.Rebate != nil && current.Rebate.Floating != nil {
+			cur := current.Rebate.Floating
+			if floating.Benchmark == "" {
+				floating.Benchmark = cur.Benchmark
+			}
+			floating.IsAutoRerate = cur.IsAutoRerate
+			floating.EffectiveDateDelay = cur.EffectiveDateDelay
+		}
+
+		proposed.Rebate = &models.RebateRate{Floating: floating}
+
+	case current.Fee != nil:
+		proposed.Fee = &models.FeeRate{
+			BaseRate:      *rate,
+			EffectiveRate: *rate,
+			EffectiveDate: *effectiveDate,
+		}
+		if set["effective-rate"] {
+			proposed.Fee.EffectiveRate = *effectiveRate
+		}
+
+	default:
+		proposed.Rebate = &models.RebateRate{Fixed: &models.FixedRate{
+			BaseRate:      *rate,
+			EffectiveRate: *rate,
+			EffectiveDate: *effectiveDate,
+		}}
+		if set["effective-rate"] {
+			proposed.Rebate.Fixed.EffectiveRate = *effectiveRate
+		}
+	}
+
+	fmt.Printf("Loan with id [%s]\n", loanId)
+	fmt.Printf("  Current rate:  %s\n", current.String())
+	fmt.Printf("  Proposed rate: %s\n", proposed.String())
+
+	resp, err := client.ProposeRerate(ctx, loanId, models.RerateProposal{Rerate: proposed})
+	printOutcome(resp, err, "Error proposing rerate: ")
+}
+
+// approveRerate approves a rerate proposed by the counterparty
+func approveRerate(ctx context.Context, rerateId string) {
+	resp, err := client.ApproveRerate(ctx, rerateId)
+	printOutcome(resp, err, "Error approving rerate: ")
+}
+
+// declineRerate declines a rerate proposed by the counterparty
+func declineRerate(ctx context.Context, rerateId string) {
+	resp, err := client.DeclineRerate(ctx, rerateId)
+	printOutcome(resp, err, "Error declining rerate: ")
+}
+
+// cancelRerate cancels a rerate we proposed
+func cancelRerate(ctx context.Context, rerateId string) {
+	resp, err := client.CancelRerate(ctx, rerateId)
+	printOutcome(resp, err, "Error canceling rerate: ")
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/EquiLend/1Source-Go/utils"
+)
+
+// proposeReturn proposes a return of securities against a loan_id
+func proposeReturn(ctx context.Context, loanId string, options []string) {
+	fs := flag.NewFlagSet("-rtp", flag.ContinueOnError)
+	quantity := fs.Int64("quantity", 0, "quantity of securities to return [required]")
+	returnDate := fs.String("return-date", utils.Today(), "date of the return (YYYY-MM-DD)")
+	settlementDate := fs.String("return-settlement-date", "", "settlement date of the return (YYYY-MM-DD), defaults to the return date")
+	settlementType := fs.String("settlement-type", "", "settlement type [DVP, FOP], defaults to the loan's")
+	collateralValue := fs.Float64("collateral-value", 0, "value of the collateral returned")
+	var settlement utils.SettlementFlags
+	settlement.Register(fs)
+	parseOptions(fs, options)
+
+	if *settlementDate == "" {
+		*settlementDate = *returnDate
+	}
+
+	if err := utils.ValidateDates(*returnDate, *settlementDate); err != nil {
+		fmt.Println("Error proposing return: ", err)
+		return
+	}
+
+	proposal := models.ReturnProposal{
+		Quantity:             *quantity,
+		ReturnDate:           *returnDate,
+		ReturnSettlementDate: *settlementDate,
+		SettlementType:       models.SettlementType(*settlementType),
+		CollateralValue:      *collateralValue,
+	}
+
+	if settlement.IsSet() {
+		psi, err := settlement.Instruction()
+		if err != nil {
+			fmt.Println("Error reading settlement instruction: ", err)
+			return
+		}
+		proposal.Settlement = []models.PartySettlementInstruction{*psi}
+	}
+
+	resp, err := client.ProposeReturn(ctx, loanId, proposal)
+	printOutcome(resp, err, "Error proposing return: ")
+}
+
+// acknowledgeReturn acknowledges a return proposed by the counterparty
+func acknowledgeReturn(ctx context.Context, returnId string, options []string) {
+	fs := flag.NewFlagSet("-rta", flag.ContinueOnError)
+	negative := fs.Bool("negative", false, "acknowledge negatively, rejecting the return")
+	description := fs.String("description", "", "reason for a negative acknowledgement")
+	var settlement utils.SettlementFlags
+	settlement.Register(fs)
+	parseOptions(fs, options)
+
+	ack := models.ReturnAcknowledgement{
+		AcknowledgementType: models.AcknowledgementTypePositive,
+		Description:         *description,
+	}
+
+	if *negative {
+		ack.AcknowledgementType = models.AcknowledgementTypeNegative
+	}
+
+	if settlement.IsSet() {
+		psi, err := settlement.Instruction()
+		if err != nil {
+			fmt.Println("Error reading settlement instruction: ", err)
+			return
+		}
+		ack.Settlement = psi
+	}
+
+	resp, err := client.AcknowledgeReturn(ctx, returnId, ack)
+	printOutcome(resp, err, "Error acknowledging return: ")
+}
+
+// cancelReturn cancels a pending return
+func cancelReturn(ctx context.Context, returnId string) {
+	resp, err := client.CancelReturn(ctx, returnId)
+	printOutcome(resp, err, "Error canceling return: ")
+}
+
+// settleReturn marks a return as settled
+func settleReturn(ctx context.Context, returnId string) {
+	resp, err := client.SettleReturn(ctx, returnId)
+	printOutcome(resp, err, "Error settling return: ")
+}
+
+// printOutcome prints the ledger response of a lifecycle command, or its error
+// Nothing is printed for a dry run, which has printed the request instead
+func printOutcome(resp string, err error, prompt string) {
+	if errors.Is(err, api.ErrDryRun) {
+		return
+	}
+
+	if err != nil {
+		log.Println(prompt, err)
+		fmt.Println(prompt, err)
+		return
+	}
+
+	fmt.Println("Successful: ", resp)
+}
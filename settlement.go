@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/EquiLend/1Source-Go/api"
+	"github.com/EquiLend/1Source-Go/models"
+	"github.com/EquiLend/1Source-Go/utils"
+)
+
+// updateSettlementStatus updates the settlement status of a loan_id
+func updateSettlementStatus(ctx context.Context, loanId string, options []string) {
+	fs := flag.NewFlagSet("-ls", flag.ContinueOnError)
+	status := fs.String("status", "", "new settlement status [PENDING, SETTLED, FAILED] [required]")
+	parseOptions(fs, options)
+
+	if *status == "" {
+		fmt.Println("Error updating settlement status: --status is required")
+		os.Exit(30)
+	}
+
+	update, err := client.UpdateLoanSettlementStatus(ctx, loanId, models.SettlementStatus(strings.ToUpper(*status)))
+	printLoanUpdate(update, err, "Error updating settlement status: ")
+}
+
+// updateSettlementInstruction replaces or amends our side's settlement
+// instruction of a loan_id
+func updateSettlementInstruction(ctx context.Context, loanId string, options []string) {
+	fs := flag.NewFlagSet("-li", flag.ContinueOnError)
+	var settlement utils.SettlementFlags
+	settlement.Register(fs)
+	amend := fs.Bool("amend", false, "only change the given fields of the current instruction")
+	venueRefId := fs.String("venue-ref-id", "", "reference of the update at the venue")
+	parseOptions(fs, options)
+
+	if !settlement.IsSet() {
+		fmt.Println("Error updating settlement instruction: settlement instruction options are required")
+		os.Exit(30)
+	}
+
+	// An amendment is validated once applied over the current instruction
+	build := settlement.Instruction
+	if *amend {
+		build = settlement.Amendment
+	}
+
+	psi, err := build()
+	if err != nil {
+		fmt.Println("Error reading settlement instruction: ", err)
+		log.Println("Error reading settlement instruction: ", err)
+		return
+	}
+
+	update := models.SettlementInstructionUpdate{
+		VenueRefId:  *venueRefId,
+		PartyRole:   psi.PartyRole,
+		Instruction: psi.Instruction,
+	}
+
+	result, err := client.UpdateSettlementInstruction(ctx, loanId, update, *amend)
+	printLoanUpdate(result, err, "Error updating settlement instruction: ")
+}
+
+// printLoanUpdate prints the outcome of a loan update and the fields of the
+// loan it changed, before and after
+func printLoanUpdate(update *api.LoanUpdate, err error, prompt string) {
+	if errors.Is(err, api.ErrDryRun) {
+		return
+	}
+
+	if err != nil {
+		log.Println(prompt, err)
+		fmt.Println(prompt, err)
+		return
+	}
+
+	fmt.Println("Successful: ", update.Message)
+
+	changes, err := update.Changes()
+	if err != nil {
+		fmt.Println("Error comparing loan versions: ", err)
+		return
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("No fields of loan [%s] have changed yet\n", update.After.LoanId)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tBEFORE\tAFTER")
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Field, c.Before, c.After)
+	}
+	w.Flush()
+}
@@ -0,0 +1,126 @@
+// Package utils contains utility functions
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/EquiLend/1Source-Go/models"
+)
+
+// SettlementFlags holds the command line options describing our side's
+// settlement instruction, either as a JSON file or as individual fields.
+// Individual fields override those read from the file
+type SettlementFlags struct {
+	File           string
+	SettlementBic  string
+	LocalAgentBic  string
+	LocalAgentName string
+	LocalAgentAcct string
+}
+
+// Register adds the settlement instruction options to a flag set
+func (sf *SettlementFlags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&sf.File, "settlement", "", "JSON file with the settlement instruction")
+	fs.StringVar(&sf.SettlementBic, "settlement-bic", "", "BIC of the settlement agent")
+	fs.StringVar(&sf.LocalAgentBic, "local-agent-bic", "", "BIC of the local agent")
+	fs.StringVar(&sf.LocalAgentName, "local-agent-name", "", "name of the local agent")
+	fs.StringVar(&sf.LocalAgentAcct, "local-agent-acct", "", "account at the local agent")
+}
+
+// IsSet reports whether any settlement instruction option was given
+func (sf *SettlementFlags) IsSet() bool {
+	return sf.File != "" || sf.SettlementBic != "" || sf.LocalAgentBic != "" ||
+		sf.LocalAgentName != "" || sf.LocalAgentAcct != ""
+}
+
+// Instruction builds the settlement instruction from the options
+func (sf *SettlementFlags) Instruction() (*models.PartySettlementInstruction, error) {
+	psi, err := sf.Amendment()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := psi.Instruction.Validate(); err != nil {
+		return nil, err
+	}
+
+	return psi, nil
+}
+
+// Amendment builds a settlement instruction from the options without
+// validating it, to be applied over an existing instruction
+func (sf *SettlementFlags) Amendment() (*models.PartySettlementInstruction, error) {
+	psi := &models.PartySettlementInstruction{}
+
+	if sf.File != "" {
+		var err error
+
+		psi, err = ReadSettlementInstruction(sf.File)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	override(&psi.Instruction.SettlementBic, sf.SettlementBic)
+	override(&psi.Instruction.LocalAgentBic, sf.LocalAgentBic)
+	override(&psi.Instruction.LocalAgentName, sf.LocalAgentName)
+	override(&psi.Instruction.LocalAgentAcct, sf.LocalAgentAcct)
+
+	return psi, nil
+}
+
+// ReadSettlementInstruction reads a settlement instruction from a JSON file.
+// The file may hold either a party settlement instruction, with partyRole and
+// instruction, or just the instruction itself
+func ReadSettlementInstruction(filename string) (*models.PartySettlementInstruction, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading settlement instruction file '%s': %w", filename, err)
+	}
+
+	var psi models.PartySettlementInstruction
+	if err := strictUnmarshal(data, &psi); err == nil {
+		return &psi, nil
+	}
+
+	if err := strictUnmarshal(data, &psi.Instruction); err != nil {
+		return nil, fmt.Errorf("parsing settlement instruction file '%s': %w", filename, err)
+	}
+
+	return &psi, nil
+}
+
+// ReadLoanProposal reads a loan proposal from a JSON file, rejecting fields
+// which are not part of the 1Source loan proposal model
+func ReadLoanProposal(filename string) (*models.LoanProposal, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var proposal models.LoanProposal
+	if err := strictUnmarshal(data, &proposal); err != nil {
+		return nil, fmt.Errorf("parsing loan proposal: %w", err)
+	}
+
+	return &proposal, nil
+}
+
+// strictUnmarshal decodes JSON, rejecting fields the model does not know
+func strictUnmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v)
+}
+
+// override replaces a field with value, unless value is empty
+func override(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
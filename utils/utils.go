@@ -2,12 +2,16 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/EquiLend/1Source-Go/api"
 	"github.com/EquiLend/1Source-Go/models"
 	"github.com/pelletier/go-toml/v2"
 )
@@ -16,7 +20,7 @@ import (
 func FileExists(filename string) bool {
 	info, err := os.Stat(filename)
 
-	if os.IsNotExist(err) {
+	if err != nil {
 		log.Printf("Configuration TOML file '%s' does not exist", filename)
 		return false
 	} else {
@@ -29,44 +33,78 @@ func FileExists(filename string) bool {
 // ReadTOML opens and reads in application configuration TOML file
 func ReadTOML(filename string) (*models.AppConfig, error) {
 	var appConfig models.AppConfig
-	var err error
-	var file *os.File
 
-	if FileExists(filename) {
-		// TOML file exists  ... open it
-		file, err = os.Open(filename)
+	if !FileExists(filename) {
+		return nil, fmt.Errorf("configuration TOML file '%s' does not exist", filename)
+	}
 
-		if err != nil {
-			log.Panic(err)
-		}
+	// TOML file exists  ... read it
+	b, err := os.ReadFile(filename)
 
-		defer func(file *os.File) {
-			err := file.Close()
-			if err != nil {
-				log.Fatal(err)
-			}
-		}(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration TOML file '%s': %w", filename, err)
+	}
+
+	// Unmarshall it from TOML to defined struct
+	err = toml.Unmarshal(b, &appConfig)
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing configuration TOML file '%s': %w", filename, err)
+	}
+
+	log.Printf("Successfully read and parsed '%s'\n", filename)
+
+	return &appConfig, nil
+}
+
+// ParseListOptions parses the options following "-g <entity>". It returns
+// nil when no options are given, in which case a single page is fetched
+//
+//	--all        fetch every page
+//	--limit N    stop after N records
+func ParseListOptions(args []string) (*api.ListOptions, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
 
-		// Read file contents
-		b, err := io.ReadAll(file)
+	opts := &api.ListOptions{}
 
-		if err != nil {
-			log.Panic(err)
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--all":
+			opts.Limit = 0
+		case "--limit":
+			if i+1 >= len(args) {
+				return nil, errors.New("--limit requires a number of records")
+			}
+			i++
+			limit, err := strconv.Atoi(args[i])
+			if err != nil || limit <= 0 {
+				return nil, fmt.Errorf("invalid --limit '%s': expected a positive number", args[i])
+			}
+			opts.Limit = limit
+		default:
+			return nil, fmt.Errorf("unknown option '%s'", args[i])
 		}
+	}
 
-		// Unmarshall it from TOML to defined struct
-		err = toml.Unmarshal(b, &appConfig)
+	return opts, nil
+}
+
+// Today returns the current date in the YYYY-MM-DD format of the 1Source REST API
+func Today() string {
+	return time.Now().Format(time.DateOnly)
+}
 
-		if err != nil {
-			log.Panic(err)
-		} else {
-			log.Printf("Successfully read and parsed '%s'\n", filename)
+// ValidateDates checks that each value is a date in the YYYY-MM-DD format
+func ValidateDates(values ...string) error {
+	for _, value := range values {
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return fmt.Errorf("invalid date '%s': expected YYYY-MM-DD", value)
 		}
-	} else {
-		fmt.Printf("Configuration TOML file '%s' does not exists\n", filename)
 	}
 
-	return &appConfig, err
+	return nil
 }
 
 // DisplayVersion prints the program version
@@ -82,23 +120,78 @@ func DisplayHelp() {
 	fmt.Println("-h, --help\tshows help message and exits")
 	fmt.Print("-v, --version\tprints version information and exits\n\n")
 	fmt.Println("-t\t\t1Source configuration TOML file [required]")
+	fmt.Println("--dry-run\tprint the requests of a command which changes the ledger instead of sending them")
 	fmt.Println("-g\t\t1Source API Endpoint to query [agreements, loans, events, parties, returns, rerates, recalls, buyins]")
+	fmt.Println("\t\t  --all\t\tfetch every page")
+	fmt.Println("\t\t  --limit N\tstop after N records")
 
 	fmt.Println("-a\t\t1Source API Endpoint to query trade agreements by agreement_id")
 	fmt.Println("-e\t\t1Source API Endpoint to query events by event_id")
+	fmt.Println("-ef\t\t1Source API Endpoint to FOLLOW new events, resuming from a checkpoint file")
+	fmt.Println("\t\t  --interval D, --lookback D, --since TIME")
+	fmt.Println("-wr\t\t1Source API Endpoint to RELAY new events to the [webhooks], resuming from a checkpoint file")
+	fmt.Println("\t\t  --interval D, --lookback D, --since TIME")
 	fmt.Println("-l\t\t1Source API Endpoint to query loans by loan_id")
-	fmt.Println("-lh\t\t1Source API Endpoint to get loan history by loan_id")
+	fmt.Println("-lh\t\t1Source API Endpoint to get loan history by loan_id, as the fields each version changed")
+	fmt.Println("\t\t  --json\t\tprint the versions as JSON")
+	fmt.Println("\t\t  --raw\t\tprint the history as returned by the API")
 	fmt.Print("-p\t\t1Source API Endpoint to query parties by party_id\n\n")
 
 	fmt.Println("-lp\t\t1Source API Endpoint to PROPOSE a loan from a JSON file")
+	fmt.Println("-lb\t\t1Source API Endpoint to PROPOSE loans in bulk from a directory, NDJSON or CSV file")
+	fmt.Println("\t\t  --mapping FILE [required for CSV], --concurrency N, --report FILE")
+	fmt.Println("-ab\t\t1Source API Endpoint to BOOK a loan from a trade agreement by agreement_id")
+	fmt.Println("\t\t  settlement instruction options [required]")
 	fmt.Println("-lc\t\t1Source API Endpoint to CANCEL a proposed loan by loan_id")
 	fmt.Println("-la\t\t1Source API Endpoint to APPROVE a proposed loan by loan_id")
+	fmt.Println("\t\t  --settlement FILE\tJSON file with our settlement instruction")
+	fmt.Println("\t\t  --settlement-bic, --local-agent-bic, --local-agent-name, --local-agent-acct")
+	fmt.Println("\t\t\t\t\tsettlement instruction fields, overriding the file")
+	fmt.Println("\t\t  --rounding-rule N, --rounding-mode MODE")
 	fmt.Println("-ld\t\t1Source API Endpoint to DECLINE a proposed loan by loan_id")
+	fmt.Println("-ls\t\t1Source API Endpoint to UPDATE the settlement status of a loan by loan_id")
+	fmt.Println("\t\t  --status STATUS [required]\tPENDING, SETTLED or FAILED")
+	fmt.Println("-li\t\t1Source API Endpoint to UPDATE our settlement instruction of a loan by loan_id")
+	fmt.Print("\t\t  settlement instruction options [required], --amend, --venue-ref-id REF\n\n")
+
+	fmt.Println("-rtp\t\t1Source API Endpoint to PROPOSE a return by loan_id")
+	fmt.Println("\t\t  --quantity N [required], --return-date DATE, --return-settlement-date DATE,")
+	fmt.Println("\t\t  --settlement-type TYPE, --collateral-value X, settlement instruction options")
+	fmt.Println("-rta\t\t1Source API Endpoint to ACKNOWLEDGE a return by return_id")
+	fmt.Println("\t\t  --negative, --description TEXT, settlement instruction options")
+	fmt.Println("-rtc\t\t1Source API Endpoint to CANCEL a pending return by return_id")
+	fmt.Print("-rts\t\t1Source API Endpoint to mark a return as SETTLED by return_id\n\n")
+
+	fmt.Println("-rcp\t\t1Source API Endpoint to PROPOSE a recall by loan_id")
+	fmt.Println("\t\t  --quantity N [required], --recall-date DATE, --recall-due-date DATE [required]")
+	fmt.Println("-rcc\t\t1Source API Endpoint to CANCEL an open recall by recall_id")
+	fmt.Print("-rco\t\t1Source API Endpoint to list OPEN recalls and days until due by loan_id, or 'all'\n\n")
+
+	fmt.Println("-rrp\t\t1Source API Endpoint to PROPOSE a rerate by loan_id")
+	fmt.Println("\t\t  --rate X | --spread X [--benchmark NAME], --effective-rate X, --effective-date DATE")
+	fmt.Println("-rra\t\t1Source API Endpoint to APPROVE a proposed rerate by rerate_id")
+	fmt.Println("-rrd\t\t1Source API Endpoint to DECLINE a proposed rerate by rerate_id")
+	fmt.Print("-rrc\t\t1Source API Endpoint to CANCEL a proposed rerate by rerate_id\n\n")
+
+	fmt.Println("-bp\t\t1Source API Endpoint to SUBMIT a buy-in by loan_id")
+	fmt.Println("\t\t  --quantity N [required], --price X [required], --currency CCY")
+	fmt.Print("-ba\t\t1Source API Endpoint to ACCEPT a submitted buy-in by buyin_id\n\n")
+
+	fmt.Println("-ms\t\t1Source API Endpoint to SYNC the local mirror into a SQLite database file")
+	fmt.Println("\t\t  --full\t\tdownload every entity again")
+	fmt.Println("-mq\t\tQUERY the local mirror offline by kind [agreements, loans, events, parties, returns, rerates, recalls, buyins]")
+	fmt.Println("\t\t  --db FILE, --status S, --ticker T, --counterparty PARTY_ID, --from DATE, --to DATE,")
+	fmt.Println("\t\t  --limit N, --json")
 	fmt.Println("")
 }
 
 // PrintResults outputs entities fetched from the 1Source REST API to the console
+// Nothing is printed for a call canceled by the user
 func PrintResults(err error, data string, prompt string, header string) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
 	if err != nil {
 		fmt.Println(prompt, err)
 	} else {